@@ -16,12 +16,12 @@ func NewBalance(config Config) Balance {
 	}
 }
 
-func (balance *Balance) HasEnoughMoneyForBuy() bool {
-	return balance.inBalanceMoney >= balance.config.TotalMoneyAmount
+func (balance *Balance) HasEnoughMoneyForBuy(moneyAmount float64) bool {
+	return balance.inBalanceMoney >= moneyAmount
 }
 
-func (balance *Balance) buy() {
-	balance.inBalanceMoney -= balance.config.TotalMoneyAmount
+func (balance *Balance) buy(moneyAmount float64) {
+	balance.inBalanceMoney -= moneyAmount
 	if balance.inBalanceMoney < 0 {
 		balance.inBalanceMoney = 0
 	}
@@ -35,14 +35,14 @@ func (balance *Balance) buy() {
 	))
 }
 
-func (balance *Balance) sell(returnMoney float64) {
+func (balance *Balance) sell(returnMoney, moneyAmount float64) {
 	//balance.buysCount--
 	//if balance.buysCount < 0 {
 	//	balance.buysCount = 0
 	//}
 
-	if returnMoney > balance.config.TotalMoneyAmount {
-		returnMoney = balance.config.TotalMoneyAmount
+	if returnMoney > moneyAmount {
+		returnMoney = moneyAmount
 	}
 
 	balance.inBalanceMoney += returnMoney
@@ -12,6 +12,8 @@ type Bot struct {
 	Config                         *Config
 	BuyIndicators                  []BuyIndicator
 	SellIndicators                 []SellIndicator
+	buySignal                      *BuySignal
+	sellSignal                     *SellSignal
 	buffer                         *Buffer
 	db                             *Database
 	orderManager                   *OrderManager
@@ -19,10 +21,23 @@ type Bot struct {
 	balance                        *Balance
 	IsTrailingSellIndicatorEnabled bool
 	trailingSellIndicator          *TrailingSellIndicator
+	stopLossSellIndicator          *StopLossSellIndicator
+	takeProfitLadderSellIndicator  *TakeProfitLadderSellIndicator
+	profitLockSellIndicator        *ProfitLockSellIndicator
+	decayingTakeProfitIndicator    *DecayingTakeProfitSellIndicator
+	desiredPriceStrategy           DesiredPriceStrategy
+	regimeClassifier               RegimeClassifier
 }
 
 func NewBot(config *Config) Bot {
+	if USE_REAL_MONEY && IsDcaEnabled(config) {
+		panic("DCA safety orders are simulated only, disable DcaEnabled for real money")
+	}
+
 	buffer := NewBuffer(resolveBufferSize(config))
+	for _, interval := range HIGHER_TIMEFRAMES {
+		buffer.AddTimeframe(interval, HIGHER_TIMEFRAME_BUFFER_SIZE)
+	}
 	db := NewDatabase(*config)
 	balance := NewBalance(*config)
 
@@ -34,6 +49,9 @@ func NewBot(config *Config) Bot {
 		IsTrailingSellIndicatorEnabled: false,
 	}
 
+	bot.desiredPriceStrategy = NewDesiredPriceStrategy(config, bot.buffer)
+	bot.regimeClassifier = NewRegimeClassifier(config, bot.buffer)
+
 	setupBuyIndicators(&bot)
 	setupSellIndicators(&bot)
 
@@ -44,6 +62,7 @@ func NewRealBot(config *Config, binanceClient *binance.Client) Bot {
 	orderManager = NewOrderManager(binanceClient)
 	bot := NewBot(config)
 	bot.orderManager = &orderManager
+	bot.RestoreIndicatorStates()
 
 	return bot
 }
@@ -52,6 +71,7 @@ func NewFuturesRealBot(config *Config, futuresClient *futures.Client) Bot {
 	futuresOrderManager := NewFuturesOrderManager(futuresClient)
 	bot := NewBot(config)
 	bot.futuresOrderManager = &futuresOrderManager
+	bot.RestoreIndicatorStates()
 
 	return bot
 }
@@ -63,20 +83,29 @@ func (bot *Bot) Kill() {
 func (bot *Bot) DoStuff(candle Candle) {
 	bot.buffer.AddCandle(candle)
 	bot.runBuyIndicators()
+	bot.runSafetyOrders()
 	bot.runSellIndicators()
+
+	if IS_REAL_ENABLED {
+		bot.SaveIndicatorStates()
+	}
 }
 
 func (bot *Bot) runBuyIndicators() {
-	signalsCount := 0
-
 	for _, indicator := range bot.BuyIndicators {
 		indicator.Update()
-		if indicator.HasSignal() {
-			signalsCount++
-		}
 	}
 
-	if len(bot.BuyIndicators) == signalsCount {
+	hasSignal := bot.buySignal.HasSignal()
+	if canTrace() {
+		TraceAddRows(bot.buffer.GetLastCandle(), "buy", bot.buySignal.GetTraceRows())
+	}
+
+	if hasSignal {
+		if canTrace() && TRACE_NOTIFICATIONS {
+			Log(fmt.Sprintf("BUY_SIGNAL:\n%s", FormatTraceRows(bot.buySignal.GetTraceRows())))
+		}
+
 		for _, indicator := range bot.BuyIndicators {
 			indicator.Finish()
 		}
@@ -84,12 +113,12 @@ func (bot *Bot) runBuyIndicators() {
 		candle := bot.buffer.GetLastCandle()
 		price := bot.buffer.GetLastCandleClosePrice()
 
-		if !USE_REAL_MONEY && !bot.balance.HasEnoughMoneyForBuy() {
+		if !USE_REAL_MONEY && !bot.balance.HasEnoughMoneyForBuy(bot.Config.TotalMoneyAmount) {
 			return
 		}
 
 		if !IS_REAL_ENABLED {
-			Log(fmt.Sprintf("BUY: %s\nExchangeRate: %f", candle.CloseTime, price))
+			Log(fmt.Sprintf("BUY: %s\nExchangeRate: %f", FormatTime(candle.CloseTime), price))
 		}
 
 		bot.buy()
@@ -97,20 +126,37 @@ func (bot *Bot) runBuyIndicators() {
 }
 
 func (bot *Bot) runSellIndicators() {
-	var eachIndicatorBuys [][]Buy
-
 	for _, indicator := range bot.SellIndicators {
 		indicator.Update()
-		hasSignal, buys := indicator.HasSignal()
-		if !hasSignal {
-			return
+	}
+
+	if bot.profitLockSellIndicator != nil {
+		for buyId, stopPrice := range bot.profitLockSellIndicator.PopMovedStops() {
+			bot.moveStopLossOrder(buyId, stopPrice)
+		}
+	}
+
+	if bot.decayingTakeProfitIndicator != nil {
+		for buyId, sellPrice := range bot.decayingTakeProfitIndicator.PopMovedPrices() {
+			bot.moveTakeProfitOrder(buyId, sellPrice)
 		}
+	}
+
+	hasSignal, buys := bot.sellSignal.HasSignal()
+	if canTrace() {
+		TraceAddRows(bot.buffer.GetLastCandle(), "sell", bot.sellSignal.GetTraceRows())
+	}
+
+	if !hasSignal {
+		return
+	}
 
-		eachIndicatorBuys = append(eachIndicatorBuys, buys)
+	if canTrace() && TRACE_NOTIFICATIONS {
+		Log(fmt.Sprintf("SELL_SIGNAL:\n%s", FormatTraceRows(bot.sellSignal.GetTraceRows())))
 	}
 
 	// Sell
-	for _, buy := range getIntersectedBuys(eachIndicatorBuys) {
+	for _, buy := range buys {
 		if IS_REAL_ENABLED {
 			if !USE_REAL_MONEY {
 				bot.sell(buy)
@@ -124,6 +170,12 @@ func (bot *Bot) runSellIndicators() {
 				return
 			}
 
+			if buy.BuyType == StopLoss && bot.isStopLossFilled(buy) {
+				bot.sell(buy)
+				bot.finishSellIndicators(buy)
+				return
+			}
+
 			if bot.IsBuySold(CANDLE_SYMBOL, buy.RealOrderId) {
 				bot.sell(buy)
 				bot.finishSellIndicators(buy)
@@ -147,7 +199,7 @@ func (bot *Bot) runSellIndicators() {
 			candle := bot.buffer.GetLastCandle()
 			Log(fmt.Sprintf(
 				"SELL: %s\nExchangeRate: %f\nRevenue: %f",
-				candle.CloseTime,
+				FormatTime(candle.CloseTime),
 				bot.buffer.GetLastCandleClosePrice(),
 				rev,
 			))
@@ -164,25 +216,38 @@ func (bot *Bot) IsBuySold(symbol string, realOrderId int64) bool {
 }
 
 func (bot *Bot) finishSellIndicators(buy Buy) {
+	// Partially sold buys keep the state of the rest, e.g. the stop price
+	if !bot.db.GetBuyById(buy.Id).IsSold() {
+		return
+	}
+
 	for _, indicator := range bot.SellIndicators {
 		indicator.Finish(buy.Id)
 	}
 }
 
 func (bot *Bot) buy() {
+	if IsDcaEnabled(bot.Config) {
+		bot.openPosition()
+		return
+	}
+
+	bot.openBuy(bot.Config.TotalMoneyAmount, bot.calcDesiredPrice(bot.buffer.GetLastCandleClosePrice()))
+}
+
+// Returns the id of the new buy, 0 when nothing was bought
+func (bot *Bot) openBuy(moneyAmount, desiredPrice float64) int64 {
 	candle := bot.buffer.GetLastCandle()
 	exchangeRate := candle.GetPrice()
 
-	desiredPrice := bot.calcDesiredPrice(exchangeRate)
-
-	if !USE_REAL_MONEY && !bot.balance.HasEnoughMoneyForBuy() {
-		return
+	if !USE_REAL_MONEY && !bot.balance.HasEnoughMoneyForBuy(moneyAmount) {
+		return 0
 	}
 
 	if IS_REAL_ENABLED {
-		coinsCount := bot.Config.TotalMoneyAmount / exchangeRate
+		coinsCount := moneyAmount / exchangeRate
 		if ENABLE_FUTURES {
-			coinsCount = (bot.Config.TotalMoneyAmount * LEVERAGE) / exchangeRate
+			coinsCount = (moneyAmount * LEVERAGE) / exchangeRate
 		}
 		rawPrice := candle.ClosePrice
 
@@ -191,7 +256,7 @@ func (bot *Bot) buy() {
 		if USE_REAL_MONEY &&
 			(!bot.HasEnoughMoneyForBuy() ||
 				!bot.CanBuyForPrice(CANDLE_SYMBOL, rawPrice)) {
-			return
+			return 0
 		}
 
 		orderId, quantity, orderPrice := bot.CreateMarketBuyOrder(candle.Symbol, rawPrice)
@@ -208,37 +273,56 @@ func (bot *Bot) buy() {
 			candle.CloseTime,
 			orderId,
 			quantity,
+			moneyAmount,
 		)
-		bot.balance.buy()
+		bot.balance.buy(moneyAmount)
 
 		buyId, _ := buyInsertResult.LastInsertId()
+		regime := bot.regimeClassifier.Classify()
+		bot.db.UpdateBuyRegime(buyId, regime)
 		bot.runAfterBuySellIndicators(buyId)
 
-		Log(fmt.Sprintf("BUY\nPrice: %f\nQuantity: %f\nOrderId: %d", orderPrice, quantity, orderId))
+		Log(fmt.Sprintf("BUY\nPrice: %f\nQuantity: %f\nOrderId: %d\nRegime: %s", orderPrice, quantity, orderId, regime))
 
 		if USE_REAL_MONEY && !bot.IsTrailingSellIndicatorEnabled {
 			upperPrice := CalcUpperPrice(orderPrice, bot.Config.HighSellPercentage)
-			bot.createAndUpdateSellOrder(buyId, upperPrice, quantity)
-		}
-	} else {
-		coinsCount := bot.Config.TotalMoneyAmount / exchangeRate
-		if ENABLE_FUTURES {
-			coinsCount = (bot.Config.TotalMoneyAmount * float64(bot.Config.Leverage)) / exchangeRate
+			if !ENABLE_FUTURES {
+				upperPrice = desiredPrice
+			}
+
+			if bot.hasTakeProfitLadder() {
+				bot.createTakeProfitLadderOrders(buyId, orderPrice, quantity)
+			} else if bot.hasStopLossOrder() {
+				bot.createSellAndStopLossOrders(buyId, upperPrice, quantity)
+			} else {
+				bot.createAndUpdateSellOrder(buyId, upperPrice, quantity)
+			}
 		}
 
-		buyInsertResult := bot.db.AddBuy(
-			CANDLE_SYMBOL,
-			coinsCount,
-			exchangeRate,
-			desiredPrice,
-			candle.CloseTime,
-		)
-		bot.balance.buy()
+		return buyId
+	}
 
-		buyId, _ := buyInsertResult.LastInsertId()
-		bot.runAfterBuySellIndicators(buyId)
-		PlotAddBuy(buyId, candle.CloseTime)
+	coinsCount := moneyAmount / exchangeRate
+	if ENABLE_FUTURES {
+		coinsCount = (moneyAmount * float64(bot.Config.Leverage)) / exchangeRate
 	}
+
+	buyInsertResult := bot.db.AddBuy(
+		CANDLE_SYMBOL,
+		coinsCount,
+		exchangeRate,
+		desiredPrice,
+		candle.CloseTime,
+		moneyAmount,
+	)
+	bot.balance.buy(moneyAmount)
+
+	buyId, _ := buyInsertResult.LastInsertId()
+	bot.db.UpdateBuyRegime(buyId, bot.regimeClassifier.Classify())
+	bot.runAfterBuySellIndicators(buyId)
+	PlotAddBuy(buyId, candle.CloseTime)
+
+	return buyId
 }
 
 func (bot *Bot) HasEnoughMoneyForBuy() bool {
@@ -272,19 +356,7 @@ func (bot *Bot) runAfterBuySellIndicators(buyId int64) {
 }
 
 func (bot *Bot) calcDesiredPrice(currentPrice float64) float64 {
-	upperPrice := CalcUpperPrice(currentPrice, bot.Config.HighSellPercentage)
-	return upperPrice
-
-	if bot.Config.DesiredPriceCandles > len(bot.buffer.GetCandles()) {
-		return upperPrice
-	}
-
-	medPrice := Median(GetClosePrices(bot.buffer.GetCandles()))
-	if upperPrice < medPrice {
-		return medPrice
-	}
-
-	return upperPrice
+	return bot.desiredPriceStrategy.CalcDesiredPrice(currentPrice)
 }
 
 func (bot *Bot) createRealMoneySellOrder(buy Buy) {
@@ -306,10 +378,14 @@ func (bot *Bot) createRealMoneySellOrder(buy Buy) {
 func (bot *Bot) sell(buy Buy) float64 {
 	candle := bot.buffer.GetLastCandle()
 	exchangeRate := candle.GetPrice()
-	rev := bot.calcRevenue(buy.Coins, bot.Config.HighSellPercentage, buy.ExchangeRate)
+	if buy.ExitPrice > 0 {
+		exchangeRate = buy.ExitPrice
+	}
+	coinsCount, realQuantity := bot.getSellQuantities(buy)
+	rev := bot.calcSellRevenue(buy, coinsCount)
 
 	if IS_REAL_ENABLED {
-		rev = bot.calcRevenue(buy.RealQuantity, bot.Config.HighSellPercentage, buy.ExchangeRate)
+		rev = bot.calcSellRevenue(buy, realQuantity)
 		//orderId := orderManager.CreateSellOrder(candle.Symbol, candle.ClosePrice, buy.RealQuantity)
 		//orderId := orderManager.CreateMarketSellOrder(candle.Symbol, candle.ClosePrice, buy.RealQuantity)
 		//bot.db.UpdateRealBuyOrderId(buy.Id, orderId)
@@ -317,7 +393,26 @@ func (bot *Bot) sell(buy Buy) float64 {
 		Log(fmt.Sprintf("SELL\nPrice: %f - %f\nRevenue: %f", buy.ExchangeRate, candle.ClosePrice, rev))
 	}
 
-	returnMoney := bot.Config.TotalMoneyAmount
+	// A take profit tranche returns its part of the money
+	moneyAmount := bot.getMoneyAmount(buy) * coinsCount / buy.Coins
+	returnMoney := moneyAmount
+
+	if buy.BuyType == StopLoss {
+		Log(fmt.Sprintf("GOT_STOP_LOSS\nOrderId: %d\nPrice: %f\n", buy.RealOrderId, exchangeRate))
+		bot.closeStopLossPosition(buy, exchangeRate, realQuantity)
+
+		if !ENABLE_FUTURES {
+			soldCoins := coinsCount
+			if IS_REAL_ENABLED {
+				soldCoins = realQuantity
+			}
+
+			rev = soldCoins * exchangeRate
+			returnMoney = rev
+		}
+	} else if coinsCount >= buy.GetUnsoldCoins()*0.999999 {
+		bot.cancelStopLossOrder(buy)
+	}
 
 	if ENABLE_FUTURES {
 		if buy.BuyType == Liquidation {
@@ -325,21 +420,22 @@ func (bot *Bot) sell(buy Buy) float64 {
 			returnMoney = 0
 
 			Log(fmt.Sprintf("GOT_LIQUIDATION\nOrderId: %d\n", buy.RealOrderId))
-		} else if buy.BuyType == TimeCancel {
-			rev = bot.calcFuturesTimeCancelRevenue(buy.Coins, buy.ExchangeRate, exchangeRate)
+		} else if buy.BuyType == TimeCancel || buy.BuyType == StopLoss {
+			rev = bot.calcFuturesTimeCancelRevenue(moneyAmount, coinsCount, buy.ExchangeRate, exchangeRate)
 
-			Log(fmt.Sprintf("GOT_TIME_CANCEL\nOrderId: %d\n", buy.RealOrderId))
+			if buy.BuyType == TimeCancel {
+				Log(fmt.Sprintf("GOT_TIME_CANCEL\nOrderId: %d\n", buy.RealOrderId))
+			}
 
-			if IS_REAL_ENABLED && USE_REAL_MONEY {
-				Log(fmt.Sprintf("CANCEL_ORDER\nOrderId: %d\n", buy.RealOrderId))
-				bot.futuresOrderManager.CancelOrder(CANDLE_SYMBOL, buy.RealOrderId)
-				bot.createAndUpdateSellOrder(buy.Id, exchangeRate, buy.RealQuantity)
+			if buy.BuyType == TimeCancel && IS_REAL_ENABLED && USE_REAL_MONEY {
+				quantity := bot.cancelSellOrders(buy, realQuantity)
+				bot.createAndUpdateSellOrder(buy.Id, exchangeRate, quantity)
 			}
 
-			if rev > bot.Config.TotalMoneyAmount {
-				returnMoney = bot.Config.TotalMoneyAmount
+			if rev > moneyAmount {
+				returnMoney = moneyAmount
 			} else {
-				returnMoney = bot.Config.TotalMoneyAmount - math.Abs(rev)
+				returnMoney = moneyAmount - math.Abs(rev)
 			}
 		}
 	}
@@ -347,26 +443,37 @@ func (bot *Bot) sell(buy Buy) float64 {
 	Log(fmt.Sprintf("JUST_ADD_SELL\nOrderId: %d\n", buy.RealOrderId))
 	bot.db.AddSell(
 		CANDLE_SYMBOL,
-		buy.Coins,
+		coinsCount,
 		exchangeRate,
 		rev,
 		buy.Id,
 		candle.CloseTime,
 	)
 
-	bot.balance.sell(returnMoney)
+	bot.balance.sell(returnMoney, moneyAmount)
 
 	PlotAddSell(buy.Id, candle.CloseTime)
 
 	return rev
 }
 
-func (bot *Bot) calcFuturesTimeCancelRevenue(coinsCount, buyPrice, currentPrice float64) float64 {
+// Coins and real quantity of the sell, the rest of the buy or one take profit tranche
+func (bot *Bot) getSellQuantities(buy Buy) (float64, float64) {
+	if buy.SellCoins > 0 {
+		return buy.SellCoins, buy.RealQuantity
+	}
+
+	unsoldCoins := buy.GetUnsoldCoins()
+
+	return unsoldCoins, buy.RealQuantity * unsoldCoins / buy.Coins
+}
+
+func (bot *Bot) calcFuturesTimeCancelRevenue(moneyAmount, coinsCount, buyPrice, currentPrice float64) float64 {
 	percentage := CalcGrowth(buyPrice, currentPrice)
 
 	if percentage < 0 {
 		//leverage := float64(bot.Config.Leverage)
-		totalMoney := bot.Config.TotalMoneyAmount // * leverage
+		totalMoney := moneyAmount // * leverage
 
 		liqPercentage := GetLeverageLiquidationPercentage(bot.Config.Leverage)
 		lose := buyPrice - currentPrice
@@ -403,27 +510,196 @@ func (bot *Bot) CreateSellOrder(symbol string, sellPrice, quantity float64) int6
 	return bot.orderManager.CreateSellOrder(symbol, sellPrice, quantity)
 }
 
-func (bot *Bot) calcRevenue(coinsCounts, upperPercentage, buyExchangeRate float64) float64 {
-	additionalPrice := (buyExchangeRate * upperPercentage) / 100
-	sellPrice := buyExchangeRate + additionalPrice
+func (bot *Bot) CancelOrder(symbol string, orderId int64) int64 {
+	if ENABLE_FUTURES {
+		return bot.futuresOrderManager.CancelOrder(symbol, orderId)
+	}
 
-	return coinsCounts * sellPrice
+	return bot.orderManager.CancelOrder(symbol, orderId)
 }
 
-func getIntersectedBuys(eachIndicatorBuys [][]Buy) []Buy {
-	count := len(eachIndicatorBuys)
-	firstBuys := eachIndicatorBuys[0]
+func (bot *Bot) hasTakeProfitLadder() bool {
+	return bot.takeProfitLadderSellIndicator != nil && IsTakeProfitLadderEnabled()
+}
+
+func (bot *Bot) hasStopLossOrder() bool {
+	return bot.stopLossSellIndicator != nil && bot.stopLossSellIndicator.HasStopPrice()
+}
+
+// Spot uses one OCO order, futures get a separate reduce only stop market order
+func (bot *Bot) createSellAndStopLossOrders(buyId int64, sellPrice, quantity float64) {
+	stopPrice := bot.stopLossSellIndicator.GetStopPrice(bot.db.GetBuyById(buyId))
+
+	if ENABLE_FUTURES {
+		bot.createAndUpdateSellOrder(buyId, sellPrice, quantity)
+		stopOrderId := bot.futuresOrderManager.CreateStopLossOrder(CANDLE_SYMBOL, stopPrice, quantity)
+		bot.db.UpdateStopOrderId(buyId, stopOrderId)
+
+		Log(fmt.Sprintf("STOP_LOSS_ORDER\nOrderId: %d\nStopPrice: %f", stopOrderId, stopPrice))
+		return
+	}
+
+	sellOrderId, stopOrderId := bot.orderManager.CreateOcoSellOrder(CANDLE_SYMBOL, sellPrice, stopPrice, quantity)
+	bot.db.UpdateRealBuyOrderId(buyId, sellOrderId)
+	bot.db.UpdateStopOrderId(buyId, stopOrderId)
+
+	Log(fmt.Sprintf(
+		"OCO_SELL_ORDER\nOrderId: %d\nUpperPrice: %f\nStopOrderId: %d\nStopPrice: %f",
+		sellOrderId,
+		sellPrice,
+		stopOrderId,
+		stopPrice,
+	))
+}
 
-	for index, _ := range eachIndicatorBuys {
-		if index == (count - 1) {
-			break
+// Without an exchange stop order the position is closed by the bot itself
+func (bot *Bot) isStopLossFilled(buy Buy) bool {
+	if buy.StopOrderId == 0 {
+		return true
+	}
+
+	return bot.IsBuySold(CANDLE_SYMBOL, buy.StopOrderId)
+}
+
+func (bot *Bot) closeStopLossPosition(buy Buy, exitPrice, quantity float64) {
+	if !IS_REAL_ENABLED || !USE_REAL_MONEY {
+		return
+	}
+
+	if buy.StopOrderId == 0 {
+		quantity = bot.cancelSellOrders(buy, quantity)
+		bot.createAndUpdateSellOrder(buy.Id, exitPrice, quantity)
+		return
+	}
+
+	// Spot OCO legs are cancelled by the exchange
+	if ENABLE_FUTURES {
+		bot.cancelSellOrders(buy, quantity)
+	}
+}
+
+// Cancels the take profit order or the open steps of the ladder, returns the quantity left to sell
+func (bot *Bot) cancelSellOrders(buy Buy, quantity float64) float64 {
+	if hasLadder, ladderQuantity := bot.cancelTakeProfitLadderOrders(buy); hasLadder {
+		return ladderQuantity
+	}
+
+	Log(fmt.Sprintf("CANCEL_ORDER\nOrderId: %d\n", buy.RealOrderId))
+	bot.CancelOrder(CANDLE_SYMBOL, buy.RealOrderId)
+
+	return quantity
+}
+
+// The exchange stop order follows the locked profit, buys without one are closed by the bot itself.
+// Spot OCO orders are placed again, cancelling one leg cancels the whole list
+func (bot *Bot) moveStopLossOrder(buyId int64, stopPrice float64) {
+	if !IS_REAL_ENABLED || !USE_REAL_MONEY {
+		return
+	}
+
+	buy := bot.db.GetBuyById(buyId)
+	if buy.StopOrderId == 0 {
+		return
+	}
+
+	_, quantity := bot.getSellQuantities(buy)
+	Log(fmt.Sprintf("CANCEL_STOP_LOSS_ORDER\nOrderId: %d\n", buy.StopOrderId))
+	bot.CancelOrder(CANDLE_SYMBOL, buy.StopOrderId)
+
+	if ENABLE_FUTURES {
+		stopOrderId := bot.futuresOrderManager.CreateStopLossOrder(CANDLE_SYMBOL, stopPrice, quantity)
+		bot.db.UpdateStopOrderId(buyId, stopOrderId)
+
+		Log(fmt.Sprintf("STOP_LOSS_ORDER_MOVED\nOrderId: %d\nStopPrice: %f", stopOrderId, stopPrice))
+		return
+	}
+
+	sellOrderId, stopOrderId := bot.orderManager.CreateOcoSellOrder(CANDLE_SYMBOL, bot.getTakeProfitPrice(buy), stopPrice, quantity)
+	bot.db.UpdateRealBuyOrderId(buyId, sellOrderId)
+	bot.db.UpdateStopOrderId(buyId, stopOrderId)
+
+	Log(fmt.Sprintf("STOP_LOSS_ORDER_MOVED\nOrderId: %d\nStopOrderId: %d\nStopPrice: %f", sellOrderId, stopOrderId, stopPrice))
+}
+
+// The take profit order follows the decaying target, spot OCO orders are placed again with the same stop
+func (bot *Bot) moveTakeProfitOrder(buyId int64, sellPrice float64) {
+	if !IS_REAL_ENABLED || !USE_REAL_MONEY {
+		return
+	}
+
+	buy := bot.db.GetBuyById(buyId)
+	_, quantity := bot.getSellQuantities(buy)
+	Log(fmt.Sprintf("CANCEL_ORDER\nOrderId: %d\n", buy.RealOrderId))
+	bot.CancelOrder(CANDLE_SYMBOL, buy.RealOrderId)
+
+	if !ENABLE_FUTURES && buy.StopOrderId != 0 {
+		stopPrice := bot.getStopPrice(buy)
+		sellOrderId, stopOrderId := bot.orderManager.CreateOcoSellOrder(CANDLE_SYMBOL, sellPrice, stopPrice, quantity)
+		bot.db.UpdateRealBuyOrderId(buyId, sellOrderId)
+		bot.db.UpdateStopOrderId(buyId, stopOrderId)
+
+		Log(fmt.Sprintf("TAKE_PROFIT_ORDER_MOVED\nOrderId: %d\nUpperPrice: %f\nStopOrderId: %d", sellOrderId, sellPrice, stopOrderId))
+		return
+	}
+
+	sellOrderId := bot.createAndUpdateSellOrder(buyId, sellPrice, quantity)
+	Log(fmt.Sprintf("TAKE_PROFIT_ORDER_MOVED\nOrderId: %d\nUpperPrice: %f", sellOrderId, sellPrice))
+}
+
+func (bot *Bot) getTakeProfitPrice(buy Buy) float64 {
+	if bot.decayingTakeProfitIndicator != nil {
+		if orderPrice, ok := bot.decayingTakeProfitIndicator.GetOrderPrice(buy.Id); ok {
+			return orderPrice
 		}
+	}
+
+	return buy.DesiredPrice
+}
 
-		secondBuys := eachIndicatorBuys[index+1]
-		firstBuys = BuySliceIntersect(firstBuys, secondBuys)
+// The higher one of the stop loss and the locked profit
+func (bot *Bot) getStopPrice(buy Buy) float64 {
+	stopPrice := 0.0
+	if bot.stopLossSellIndicator != nil {
+		stopPrice = bot.stopLossSellIndicator.GetStopPrice(buy)
 	}
 
-	return firstBuys
+	if bot.profitLockSellIndicator != nil {
+		if lockedPrice, ok := bot.profitLockSellIndicator.GetStopPrice(buy.Id); ok && lockedPrice > stopPrice {
+			stopPrice = lockedPrice
+		}
+	}
+
+	return stopPrice
+}
+
+func (bot *Bot) cancelStopLossOrder(buy Buy) {
+	if !IS_REAL_ENABLED || !USE_REAL_MONEY || !ENABLE_FUTURES || buy.StopOrderId == 0 || buy.BuyType == Liquidation {
+		return
+	}
+
+	Log(fmt.Sprintf("CANCEL_STOP_LOSS_ORDER\nOrderId: %d\n", buy.StopOrderId))
+	bot.CancelOrder(CANDLE_SYMBOL, buy.StopOrderId)
+}
+
+// Spot buys and DCA layers are sold for the desired price, futures ones for the fixed percentage,
+// take profit tranches and decaying targets for their exit price
+func (bot *Bot) calcSellRevenue(buy Buy, coinsCount float64) float64 {
+	if buy.BuyType == 0 && buy.ExitPrice > 0 {
+		return coinsCount * buy.ExitPrice
+	}
+
+	if (!ENABLE_FUTURES || buy.PositionId != 0) && buy.DesiredPrice > 0 {
+		return coinsCount * buy.DesiredPrice
+	}
+
+	return bot.calcRevenue(coinsCount, bot.Config.HighSellPercentage, buy.ExchangeRate)
+}
+
+func (bot *Bot) calcRevenue(coinsCounts, upperPercentage, buyExchangeRate float64) float64 {
+	additionalPrice := (buyExchangeRate * upperPercentage) / 100
+	sellPrice := buyExchangeRate + additionalPrice
+
+	return coinsCounts * sellPrice
 }
 
 func resolveBufferSize(config *Config) int {
@@ -433,99 +709,84 @@ func resolveBufferSize(config *Config) int {
 		config.DesiredPriceCandles,
 		config.GradientDescentCandles,
 		config.GradientDescentPeriod,
+		config.RsiPeriod + 1,
+		config.MacdSlowPeriod + config.MacdSignalPeriod,
+		config.BollingerPeriod,
+		config.StochasticFastKPeriod + config.StochasticSlowKPeriod + config.StochasticSlowDPeriod,
+		config.VolumeSpikePeriod + 1,
+		config.TakerImbalancePeriod,
+		config.VwapPeriod,
+		config.ObvPeriod + 1,
+		config.StopLossAtrPeriod + 1,
+		config.DesiredPriceAtrPeriod + 1,
+		config.DesiredPriceSwingCandles,
+		config.RegimeAdxPeriod * 2,
+		config.RegimeVolatilityPeriod + 1,
 	}) + 1
 }
 
 func setupBuyIndicators(bot *Bot) {
-	//backTrailingBuyIndicator := NewBackTrailingBuyIndicator(
-	//	bot.Config,
-	//	bot.buffer,
-	//	bot.db,
-	//)
-
-	//buysCountIndicator := NewBuysCountIndicator(
-	//	bot.Config,
-	//	bot.buffer,
-	//	bot.db,
-	//)
-
-	//waitForPeriodIndicator := NewWaitForPeriodIndicator(
-	//	bot.Config,
-	//	bot.buffer,
-	//	bot.db,
-	//)
-
-	bigFallIndicator := NewBigFallIndicator(
-		bot.Config,
-		bot.buffer,
-		bot.db,
-	)
-
-	lessThanPreviousBuyIndicator := NewLessThanPreviousBuyIndicator(
-		bot.Config,
-		bot.buffer,
-		bot.db,
-	)
-
-	//gradientDescentIndicator := NewGradientDescentIndicator(
-	//	bot.Config,
-	//	bot.buffer,
-	//	bot.db,
-	//)
-
-	bot.BuyIndicators = []BuyIndicator{
-		//&backTrailingBuyIndicator,
-		//&buysCountIndicator,
-		//&waitForPeriodIndicator,
-		&bigFallIndicator,
-		&lessThanPreviousBuyIndicator,
-		//&gradientDescentIndicator,
-	}
+	bot.SetBuySignal(GetBuySignalExpression(bot.Config))
 }
 
 func setupSellIndicators(bot *Bot) {
+	expression := SPOT_SELL_SIGNAL_EXPRESSION
 	if ENABLE_FUTURES {
-		leverageSellIndicator := NewLeverageSellIndicator(
-			bot.Config,
-			bot.buffer,
-			bot.db,
-		)
-
-		bot.SellIndicators = []SellIndicator{
-			//&highPercentageSellIndicator,
-			&leverageSellIndicator,
-			//&trailingSellIndicator,
-		}
-		return
+		expression = FUTURES_SELL_SIGNAL_EXPRESSION
 	}
 
-	//highPercentageSellIndicator := NewHighPercentageSellIndicator(
-	//	bot.Config,
-	//	bot.buffer,
-	//	bot.db,
-	//)
+	bot.SetSellSignal(expression)
+}
 
-	desiredPriceSellIndicator := NewDesiredPriceSellIndicator(
+// Replaces the buy indicators, the streaming ones see only the candles added after
+func (bot *Bot) SetBuySignal(expression string) {
+	buySignal := NewBuySignal(
+		expression,
 		bot.Config,
 		bot.buffer,
 		bot.db,
 	)
 
-	trailingSellIndicator := NewTrailingSellIndicator(
+	bot.buySignal = &buySignal
+	bot.BuyIndicators = buySignal.GetIndicators()
+}
+
+func (bot *Bot) SetSellSignal(expression string) {
+	sellSignal := NewSellSignal(
+		expression,
 		bot.Config,
 		bot.buffer,
 		bot.db,
 	)
 
-	bot.SellIndicators = []SellIndicator{
-		//&highPercentageSellIndicator,
-		&desiredPriceSellIndicator,
-		//&trailingSellIndicator,
-	}
+	bot.sellSignal = &sellSignal
+	bot.SellIndicators = sellSignal.GetIndicators()
+
+	bot.IsTrailingSellIndicatorEnabled = false
+	bot.trailingSellIndicator = nil
+	bot.stopLossSellIndicator = nil
+	bot.takeProfitLadderSellIndicator = nil
+	bot.profitLockSellIndicator = nil
+	bot.decayingTakeProfitIndicator = nil
 
 	bot.setIsTrailingSellIndicatorEnabled()
-	if bot.IsTrailingSellIndicatorEnabled {
-		bot.trailingSellIndicator = &trailingSellIndicator
+
+	for _, indicator := range bot.SellIndicators {
+		if stopLossSellIndicator, ok := indicator.(*StopLossSellIndicator); ok {
+			bot.stopLossSellIndicator = stopLossSellIndicator
+		}
+
+		if takeProfitLadderSellIndicator, ok := indicator.(*TakeProfitLadderSellIndicator); ok {
+			bot.takeProfitLadderSellIndicator = takeProfitLadderSellIndicator
+		}
+
+		if profitLockSellIndicator, ok := indicator.(*ProfitLockSellIndicator); ok {
+			bot.profitLockSellIndicator = profitLockSellIndicator
+		}
+
+		if decayingTakeProfitIndicator, ok := indicator.(*DecayingTakeProfitSellIndicator); ok {
+			bot.decayingTakeProfitIndicator = decayingTakeProfitIndicator
+		}
 	}
 }
 
@@ -533,6 +794,7 @@ func (bot *Bot) setIsTrailingSellIndicatorEnabled() {
 	for _, indicator := range bot.SellIndicators {
 		if "*main.TrailingSellIndicator" == reflect.TypeOf(indicator).String() {
 			bot.IsTrailingSellIndicatorEnabled = true
+			bot.trailingSellIndicator = indicator.(*TrailingSellIndicator)
 			return
 		}
 	}
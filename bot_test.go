@@ -0,0 +1,197 @@
+package main
+
+import "testing"
+
+func assertSteps(t *testing.T, expectedBuys, expectedSells []int, steps []testStep) {
+	t.Helper()
+
+	if len(expectedBuys) != len(steps) || len(expectedSells) != len(steps) {
+		t.Fatalf("expected %d steps, got %d", len(expectedBuys), len(steps))
+	}
+
+	for index, step := range steps {
+		if step.BuysCount != expectedBuys[index] || step.SellsCount != expectedSells[index] {
+			t.Errorf(
+				"candle %d: expected %d buys and %d sells, got %d and %d",
+				index,
+				expectedBuys[index],
+				expectedSells[index],
+				step.BuysCount,
+				step.SellsCount,
+			)
+		}
+	}
+}
+
+func TestBotBuysOnFallAndSellsOnTakeProfit(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		config := newTestConfig()
+		bot := newTestBot(t, config, "BigFall AND LessThanPreviousBuy", "HighPercentage")
+
+		steps := runTestCandles(bot, loadTestCandles("big_fall_rebound.csv"))
+		assertSteps(
+			t,
+			[]int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1},
+			[]int{0, 0, 0, 0, 0, 0, 0, 1, 1, 1},
+			steps,
+		)
+
+		if !steps[5].BuySignals["BigFall"] || steps[6].BuySignals["LessThanPreviousBuy"] {
+			t.Errorf("unexpected buy signals: %v, %v", steps[5].BuySignals, steps[6].BuySignals)
+		}
+		assertIds(t, "sell signal", []int64{1}, steps[7].SellSignals["HighPercentage"])
+
+		buys := fetchTestBuys(bot.db)
+		assertFloat(t, "buy price", 97, buys[0].ExchangeRate)
+		if !buys[0].IsSold() {
+			t.Errorf("expected the buy to be sold")
+		}
+
+		sells := fetchTestSells(bot.db)
+		assertFloat(t, "sold coins", buys[0].Coins, sells[0].Coins)
+		if ENABLE_FUTURES {
+			assertFloat(t, "revenue", buys[0].Coins*CalcUpperPrice(97, config.HighSellPercentage), sells[0].Revenue)
+		} else {
+			assertFloat(t, "revenue", buys[0].Coins*buys[0].DesiredPrice, sells[0].Revenue)
+		}
+
+		assertFloat(t, "balance", BALANCE_MONEY, bot.balance.inBalanceMoney)
+	})
+}
+
+func TestBotKeepsMoneyOfUnsoldBuys(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		config := newTestConfig()
+		bot := newTestBot(t, config, "BigFall AND LessThanPreviousBuy", "HighPercentage")
+
+		runTestCandles(bot, newTestPriceCandles(100, 100, 100, 100, 100, 97, 96, 90))
+
+		assertIds(t, "buys", []int64{1, 2, 3}, buyIds(fetchTestBuys(bot.db)))
+		assertFloat(t, "balance", BALANCE_MONEY-3*config.TotalMoneyAmount, bot.balance.inBalanceMoney)
+	})
+}
+
+func TestBotLiquidation(t *testing.T) {
+	setTestFutures(t, true)
+
+	config := newTestConfig()
+	config.WaitAfterLastBuyPeriod = 10
+	bot := newTestBot(t, config, "BigFall AND WaitForPeriod", "Leverage")
+
+	steps := runTestCandles(bot, newTestCandles([]testCandle{
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 100, Low: 97, Close: 97},
+		{Open: 97, High: 97, Low: 97, Close: 97},
+		{Open: 97, High: 97, Low: 80, Close: 97},
+	}))
+	assertSteps(
+		t,
+		[]int{0, 0, 0, 0, 0, 1, 1, 1},
+		[]int{0, 0, 0, 0, 0, 0, 0, 1},
+		steps,
+	)
+
+	sells := fetchTestSells(bot.db)
+	assertFloat(t, "revenue", 0, sells[0].Revenue)
+	assertFloat(t, "balance", BALANCE_MONEY-config.TotalMoneyAmount, bot.balance.inBalanceMoney)
+}
+
+func TestBotStopLossBeforeTakeProfit(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		config := newTestConfig()
+		bot := newTestBot(t, config, "BigFall AND LessThanPreviousBuy", "HighPercentage OR StopLoss")
+
+		steps := runTestCandles(bot, newTestCandles([]testCandle{
+			{Open: 100, High: 100, Low: 100, Close: 100},
+			{Open: 100, High: 100, Low: 100, Close: 100},
+			{Open: 100, High: 100, Low: 100, Close: 100},
+			{Open: 100, High: 100, Low: 100, Close: 100},
+			{Open: 100, High: 100, Low: 100, Close: 100},
+			{Open: 100, High: 100, Low: 97, Close: 97},
+			{Open: 97, High: 97, Low: 92, Close: 94},
+		}))
+		assertIds(t, "sell signal", []int64{1}, steps[6].SellSignals["StopLoss"])
+
+		sells := fetchTestSells(bot.db)
+		if len(sells) != 1 {
+			t.Fatalf("expected one sell, got %d", len(sells))
+		}
+		assertFloat(t, "exit price", CalcBottomPrice(97, config.StopLossPercentage), sells[0].ExchangeRate)
+	})
+}
+
+func TestBotAveragesDcaPosition(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		config := newTestConfig()
+		config.DcaEnabled = 1
+		config.DcaSafetyOrdersCount = 1
+		config.DcaPriceDeviationPercentage = 2
+		config.DcaVolumeScale = 1
+		config.DcaStepScale = 1
+
+		sellExpression := "DesiredPrice"
+		if ENABLE_FUTURES {
+			sellExpression = "Leverage"
+		}
+		bot := newTestBot(t, config, "BigFall AND LessThanPreviousBuy", sellExpression)
+
+		steps := runTestCandles(bot, newTestPriceCandles(100, 100, 100, 100, 100, 97, 95, 96, 97))
+		assertSteps(
+			t,
+			[]int{0, 0, 0, 0, 0, 1, 2, 2, 2},
+			[]int{0, 0, 0, 0, 0, 0, 0, 0, 2},
+			steps,
+		)
+
+		// Both layers are sold at the take profit of the average entry price
+		buys := fetchTestBuys(bot.db)
+		averagePrice := (buys[0].Coins*97 + buys[1].Coins*95) / (buys[0].Coins + buys[1].Coins)
+		assertFloat(t, "take profit", CalcUpperPrice(averagePrice, config.HighSellPercentage), buys[1].DesiredPrice)
+		assertIds(t, "position", []int64{1, 1}, []int64{buys[0].PositionId, buys[1].PositionId})
+	})
+}
+
+func getTestTotalRevenue(db *Database) float64 {
+	if ENABLE_FUTURES {
+		return db.GetFuturesTotalRevenue()
+	}
+
+	return db.GetTotalRevenue()
+}
+
+func TestBotAccountsPartialTakeProfit(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		ladder := TAKE_PROFIT_LADDER
+		TAKE_PROFIT_LADDER = []TakeProfitStep{{Percentage: 50, TargetPercentage: 0.5}, {Percentage: 50, TargetPercentage: 1.2}}
+		t.Cleanup(func() { TAKE_PROFIT_LADDER = ladder })
+
+		config := newTestConfig()
+		bot := newTestBot(t, config, "BigFall AND LessThanPreviousBuy", "TakeProfitLadder")
+
+		firstStepPrice := CalcUpperPrice(97, 0.5)
+		lastStepPrice := CalcUpperPrice(97, 1.2)
+		candles := newTestPriceCandles(100, 100, 100, 100, 100, 97, firstStepPrice, lastStepPrice)
+
+		steps := runTestCandles(bot, candles[:7])
+		assertSteps(t, []int{0, 0, 0, 0, 0, 1, 1}, []int{0, 0, 0, 0, 0, 0, 1}, steps)
+
+		buy := fetchTestBuys(bot.db)[0]
+		firstStepCoins := buy.Coins / 2
+		assertFloat(t, "half sold revenue", firstStepCoins*(firstStepPrice-97), getTestTotalRevenue(bot.db))
+		assertFloat(t, "half sold unsold buys", 1, float64(bot.db.CountUnsoldBuys()))
+		assertFloat(t, "half sold avg sell time", 0, bot.db.GetAvgSellTime())
+
+		steps = runTestCandles(bot, candles[7:])
+		assertSteps(t, []int{1}, []int{2}, steps)
+
+		expected := firstStepCoins*(firstStepPrice-97) + (buy.Coins-firstStepCoins)*(lastStepPrice-97)
+		assertFloat(t, "sold revenue", expected, getTestTotalRevenue(bot.db))
+		assertFloat(t, "sold unsold buys", 0, float64(bot.db.CountUnsoldBuys()))
+		// Sold by the last step, two candles after the buy
+		assertFloat(t, "sold avg sell time", 2.0/(24*60), bot.db.GetAvgSellTime())
+	})
+}
@@ -1,8 +1,12 @@
 package main
 
 type Buffer struct {
-	candles []Candle
-	maxSize int
+	candles         []Candle
+	maxSize         int
+	streamings      map[string]StreamingIndicator
+	streamingsOrder []StreamingIndicator
+	timeframes      map[string]*TimeframeBuffer
+	timeframesOrder []*TimeframeBuffer
 }
 
 func NewBuffer(maxSize int) Buffer {
@@ -10,6 +14,14 @@ func NewBuffer(maxSize int) Buffer {
 }
 
 func (buffer *Buffer) AddCandle(candle Candle) {
+	for _, indicator := range buffer.streamingsOrder {
+		indicator.Add(candle)
+	}
+
+	for _, timeframe := range buffer.timeframesOrder {
+		timeframe.AddBaseCandle(candle)
+	}
+
 	buffer.candles = append(buffer.candles, candle)
 	realSize := len(buffer.candles)
 	if realSize <= buffer.maxSize {
@@ -20,6 +32,10 @@ func (buffer *Buffer) AddCandle(candle Candle) {
 	buffer.candles = tempCandles
 }
 
+func (buffer *Buffer) GetMaxSize() int {
+	return buffer.maxSize
+}
+
 func (buffer *Buffer) GetCandles() []Candle {
 	return buffer.candles
 }
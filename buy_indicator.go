@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"github.com/markcheno/go-talib"
+	"math"
+	"time"
 )
 
 type BuyIndicator interface {
@@ -50,7 +52,7 @@ func (indicator *BackTrailingBuyIndicator) IsStarted() bool {
 func (indicator *BackTrailingBuyIndicator) Start() {
 	candle := indicator.buffer.GetLastCandle()
 
-	Log(fmt.Sprintf("BackTrailingBuyIndicator__STARTED: %s\nExchangeRate: %f", candle.CloseTime, candle.ClosePrice))
+	Log(fmt.Sprintf("BackTrailingBuyIndicator__STARTED: %s\nExchangeRate: %f", FormatTime(candle.CloseTime), candle.ClosePrice))
 
 	price := indicator.buffer.GetLastCandleClosePrice()
 	indicator.upperStopPrice = indicator.calculateStopPrice(
@@ -88,7 +90,7 @@ func (indicator *BackTrailingBuyIndicator) Update() {
 
 	if newUpperStopPrice < indicator.upperStopPrice {
 		candle := indicator.buffer.GetLastCandle()
-		Log(fmt.Sprintf("BackTrailingBuyIndicator__STOP_MOVED: %s\nStopPrice: %f", candle.CloseTime, newUpperStopPrice))
+		Log(fmt.Sprintf("BackTrailingBuyIndicator__STOP_MOVED: %s\nStopPrice: %f", FormatTime(candle.CloseTime), newUpperStopPrice))
 
 		indicator.upperStopPrice = newUpperStopPrice
 	}
@@ -99,7 +101,7 @@ func (indicator *BackTrailingBuyIndicator) Update() {
 
 func (indicator *BackTrailingBuyIndicator) Finish() {
 	candle := indicator.buffer.GetLastCandle()
-	Log(fmt.Sprintf("BackTrailingBuyIndicator__FINISHED: %s\nExchangeRate: %f", candle.CloseTime, candle.ClosePrice))
+	Log(fmt.Sprintf("BackTrailingBuyIndicator__FINISHED: %s\nExchangeRate: %f", FormatTime(candle.CloseTime), candle.ClosePrice))
 
 	indicator.isStarted = false
 	indicator.updatesCount = 0
@@ -132,6 +134,55 @@ func (indicator *BackTrailingBuyIndicator) calculateStopPrice(closePrice, percen
 	return closePrice + ((closePrice * percentage) / 100)
 }
 
+func (indicator *BackTrailingBuyIndicator) GetTraceValues() []TraceValue {
+	return []TraceValue{
+		{Name: "is_started", Value: boolToTraceValue(indicator.isStarted)},
+		{Name: "last_price", Value: indicator.lastPrice},
+		{Name: "upper_stop_price", Value: indicator.upperStopPrice},
+		{Name: "updates_count", Value: float64(indicator.updatesCount)},
+	}
+}
+
+type backTrailingBuyState struct {
+	IsStarted                bool    `json:"is_started"`
+	HasSignal                bool    `json:"has_signal"`
+	LastPrice                float64 `json:"last_price"`
+	UpperStopPrice           float64 `json:"upper_stop_price"`
+	UpdatesCount             int     `json:"updates_count"`
+	UpdatedTimesBeforeFinish int     `json:"updated_times_before_finish"`
+}
+
+func (indicator *BackTrailingBuyIndicator) GetStateName() string {
+	return "BackTrailing"
+}
+
+func (indicator *BackTrailingBuyIndicator) GetStateVersion() int {
+	return 1
+}
+
+func (indicator *BackTrailingBuyIndicator) SaveState() string {
+	return marshalIndicatorState(backTrailingBuyState{
+		IsStarted:                indicator.isStarted,
+		HasSignal:                indicator.hasSignal,
+		LastPrice:                indicator.lastPrice,
+		UpperStopPrice:           indicator.upperStopPrice,
+		UpdatesCount:             indicator.updatesCount,
+		UpdatedTimesBeforeFinish: indicator.updatedTimesBeforeFinish,
+	})
+}
+
+func (indicator *BackTrailingBuyIndicator) RestoreState(encoded string) {
+	state := backTrailingBuyState{}
+	unmarshalIndicatorState(encoded, &state)
+
+	indicator.isStarted = state.IsStarted
+	indicator.hasSignal = state.HasSignal
+	indicator.lastPrice = state.LastPrice
+	indicator.upperStopPrice = state.UpperStopPrice
+	indicator.updatesCount = state.UpdatesCount
+	indicator.updatedTimesBeforeFinish = state.UpdatedTimesBeforeFinish
+}
+
 // --------------------------------
 
 type BuysCountIndicator struct {
@@ -170,6 +221,10 @@ func (indicator *BuysCountIndicator) Update() {
 func (indicator *BuysCountIndicator) Finish() {
 }
 
+func (indicator *BuysCountIndicator) GetTraceValues() []TraceValue {
+	return []TraceValue{{Name: "unsold_buys_count", Value: float64(indicator.db.CountUnsoldBuys())}}
+}
+
 // --------------------------------
 
 type WaitForPeriodIndicator struct {
@@ -208,12 +263,24 @@ func (indicator *WaitForPeriodIndicator) Update() {
 func (indicator *WaitForPeriodIndicator) Finish() {
 }
 
+func (indicator *WaitForPeriodIndicator) GetTraceValues() []TraceValue {
+	hasBuy, buy := indicator.db.GetLastBuy()
+	if !hasBuy {
+		return []TraceValue{}
+	}
+
+	minutes := indicator.buffer.GetLastCandle().CloseTime.Sub(buy.CreatedAt).Minutes()
+
+	return []TraceValue{{Name: "minutes_since_last_buy", Value: minutes}}
+}
+
 // ---------------------------------------
 
 type BigFallIndicator struct {
 	config *Config
 	buffer *Buffer
 	db     *Database
+	sma    *StreamingSma
 }
 
 func NewBigFallIndicator(
@@ -225,6 +292,7 @@ func NewBigFallIndicator(
 		config: config,
 		buffer: buffer,
 		db:     db,
+		sma:    buffer.GetSma(config.BigFallSmoothPeriod, getSmoothedHistorySize(config, config.BigFallSmoothPeriod)),
 	}
 }
 
@@ -239,8 +307,7 @@ func (indicator *BigFallIndicator) HasSignal() bool {
 	//fallPercentage := -1 * CalcGrowth(firstCandle, lastCandle)
 
 	// ----------------------
-	closePrices := GetClosePrices(indicator.buffer.GetCandles())
-	smoothedPrices := FilterZeroPrices(talib.Sma(closePrices, indicator.config.BigFallSmoothPeriod))
+	smoothedPrices := indicator.sma.GetValues()
 	smoothedLen := len(smoothedPrices)
 	if 4 > smoothedLen {
 		return false
@@ -267,12 +334,42 @@ func (indicator *BigFallIndicator) Update() {
 func (indicator *BigFallIndicator) Finish() {
 }
 
+func (indicator *BigFallIndicator) GetTraceValues() []TraceValue {
+	smoothedPrices := indicator.sma.GetValues()
+	if 0 == len(smoothedPrices) {
+		return []TraceValue{}
+	}
+
+	firstPrice := smoothedPrices[0]
+	lastPrice := smoothedPrices[len(smoothedPrices)-1]
+
+	return []TraceValue{
+		{Name: "first_smoothed_price", Value: firstPrice},
+		{Name: "last_smoothed_price", Value: lastPrice},
+		{Name: "fall_percentage", Value: -1 * CalcGrowth(firstPrice, lastPrice)},
+	}
+}
+
+// The smoothed prices across the candles of the original indicators, the periods of the newer
+// indicators grow the buffer but not the lookback of BigFall and GradientDescent
+func getSmoothedHistorySize(config *Config, period int) int {
+	lookbackSize := MaxInt([]int{
+		config.BigFallCandlesCount,
+		config.DesiredPriceCandles,
+		config.GradientDescentCandles,
+		config.GradientDescentPeriod,
+	}) + 1
+
+	return lookbackSize - period + 1
+}
+
 // ---------------------------------------
 
 type GradientDescentIndicator struct {
 	config *Config
 	buffer *Buffer
 	db     *Database
+	sma    *StreamingSma
 }
 
 func NewGradientDescentIndicator(
@@ -284,6 +381,7 @@ func NewGradientDescentIndicator(
 		config: config,
 		buffer: buffer,
 		db:     db,
+		sma:    buffer.GetSma(config.GradientDescentPeriod, getSmoothedHistorySize(config, config.GradientDescentPeriod)),
 	}
 }
 
@@ -293,8 +391,7 @@ func (indicator *GradientDescentIndicator) HasSignal() bool {
 		return false
 	}
 
-	closePrices := GetClosePrices(indicator.buffer.GetCandles())
-	smoothedPrices := FilterZeroPrices(talib.Sma(closePrices, indicator.config.GradientDescentPeriod))
+	smoothedPrices := indicator.sma.GetValues()
 	smoothedLen := len(smoothedPrices)
 	if 4 > smoothedLen {
 		return false
@@ -322,6 +419,21 @@ func (indicator *GradientDescentIndicator) HasSignal() bool {
 		gradient <= indicator.config.GradientDescentGradient
 }
 
+func (indicator *GradientDescentIndicator) GetTraceValues() []TraceValue {
+	smoothedPrices := indicator.sma.GetValues()
+	if 0 == len(smoothedPrices) {
+		return []TraceValue{}
+	}
+
+	x := float64(indicator.config.GradientDescentCandles)
+	y := smoothedPrices[0] - smoothedPrices[len(smoothedPrices)-1]
+
+	return []TraceValue{
+		{Name: "last_smoothed_price", Value: smoothedPrices[len(smoothedPrices)-1]},
+		{Name: "gradient", Value: y / x},
+	}
+}
+
 func (indicator *GradientDescentIndicator) mapGradients(smoothedPrices []float64) []float64 {
 	var gradients []float64
 
@@ -384,6 +496,11 @@ func NewLessThanPreviousBuyIndicator(
 }
 
 func (indicator *LessThanPreviousBuyIndicator) HasSignal() bool {
+	// The DCA position is averaged down by safety orders, see dca.go
+	if IsDcaEnabled(indicator.config) {
+		return true
+	}
+
 	if indicator.db.GetBuysCount() == 0 {
 		return true
 	}
@@ -408,3 +525,939 @@ func (indicator *LessThanPreviousBuyIndicator) Update() {
 
 func (indicator *LessThanPreviousBuyIndicator) Finish() {
 }
+
+func (indicator *LessThanPreviousBuyIndicator) GetTraceValues() []TraceValue {
+	hasValue, buy := indicator.db.GetLastUnsoldBuy()
+	if !hasValue {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{{Name: "previous_buy_price", Value: buy.ExchangeRate}}
+}
+
+// ---------------------------------------
+
+type RsiIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+	rsi    *StreamingRsi
+}
+
+func NewRsiIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) RsiIndicator {
+	return RsiIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+		rsi:    buffer.GetRsi(config.RsiPeriod),
+	}
+}
+
+func (indicator *RsiIndicator) HasSignal() bool {
+	count := len(indicator.buffer.GetCandles())
+	if (indicator.config.RsiPeriod + 1) > count {
+		return false
+	}
+
+	return indicator.rsi.IsReady() && indicator.rsi.Last() <= indicator.config.RsiOversold
+}
+
+func (indicator *RsiIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *RsiIndicator) Start() {
+}
+
+func (indicator *RsiIndicator) Update() {
+}
+
+func (indicator *RsiIndicator) Finish() {
+}
+
+func (indicator *RsiIndicator) GetTraceValues() []TraceValue {
+	return []TraceValue{{Name: "rsi", Value: indicator.rsi.Last()}}
+}
+
+// ---------------------------------------
+
+type MacdIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewMacdIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) MacdIndicator {
+	return MacdIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// MACD line crosses the signal line from below on the last candle
+func (indicator *MacdIndicator) HasSignal() bool {
+	macd, signal, ok := indicator.calcValues()
+	if !ok {
+		return false
+	}
+
+	return macd[0] <= signal[0] && macd[1] > signal[1]
+}
+
+// MACD and signal lines of the previous and the last candle
+func (indicator *MacdIndicator) calcValues() ([]float64, []float64, bool) {
+	count := len(indicator.buffer.GetCandles())
+	if (indicator.config.MacdSlowPeriod + indicator.config.MacdSignalPeriod) > count {
+		return nil, nil, false
+	}
+
+	closePrices := GetClosePrices(indicator.buffer.GetCandles())
+	macd, signal, _ := talib.Macd(
+		closePrices,
+		indicator.config.MacdFastPeriod,
+		indicator.config.MacdSlowPeriod,
+		indicator.config.MacdSignalPeriod,
+	)
+
+	last := len(macd) - 1
+
+	return macd[last-1:], signal[last-1:], true
+}
+
+func (indicator *MacdIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *MacdIndicator) Start() {
+}
+
+func (indicator *MacdIndicator) Update() {
+}
+
+func (indicator *MacdIndicator) Finish() {
+}
+
+func (indicator *MacdIndicator) GetTraceValues() []TraceValue {
+	macd, signal, ok := indicator.calcValues()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "macd", Value: macd[1]},
+		{Name: "macd_signal", Value: signal[1]},
+		{Name: "macd_histogram", Value: macd[1] - signal[1]},
+	}
+}
+
+// ---------------------------------------
+
+type BollingerIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewBollingerIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) BollingerIndicator {
+	return BollingerIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// The last candle touches the lower band
+func (indicator *BollingerIndicator) HasSignal() bool {
+	_, _, lower, ok := indicator.calcBands()
+	if !ok {
+		return false
+	}
+
+	return indicator.buffer.GetLastCandle().LowPrice <= lower
+}
+
+// Upper, middle and lower band of the last candle
+func (indicator *BollingerIndicator) calcBands() (float64, float64, float64, bool) {
+	candles := indicator.buffer.GetCandles()
+	if indicator.config.BollingerPeriod > len(candles) {
+		return 0, 0, 0, false
+	}
+
+	closePrices := GetClosePrices(candles)
+	upper, middle, lower := talib.BBands(
+		closePrices,
+		indicator.config.BollingerPeriod,
+		indicator.config.BollingerDeviation,
+		indicator.config.BollingerDeviation,
+		talib.SMA,
+	)
+
+	last := len(lower) - 1
+
+	return upper[last], middle[last], lower[last], true
+}
+
+func (indicator *BollingerIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *BollingerIndicator) Start() {
+}
+
+func (indicator *BollingerIndicator) Update() {
+}
+
+func (indicator *BollingerIndicator) Finish() {
+}
+
+func (indicator *BollingerIndicator) GetTraceValues() []TraceValue {
+	upper, middle, lower, ok := indicator.calcBands()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "upper_band", Value: upper},
+		{Name: "middle_band", Value: middle},
+		{Name: "lower_band", Value: lower},
+	}
+}
+
+// ---------------------------------------
+
+type StochasticIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewStochasticIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) StochasticIndicator {
+	return StochasticIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// %K crosses %D from below in the oversold zone
+func (indicator *StochasticIndicator) HasSignal() bool {
+	slowK, slowD, ok := indicator.calcValues()
+	if !ok {
+		return false
+	}
+
+	return slowK[1] <= indicator.config.StochasticOversold &&
+		slowK[0] <= slowD[0] &&
+		slowK[1] > slowD[1]
+}
+
+// %K and %D of the previous and the last candle
+func (indicator *StochasticIndicator) calcValues() ([]float64, []float64, bool) {
+	candles := indicator.buffer.GetCandles()
+	required := indicator.config.StochasticFastKPeriod +
+		indicator.config.StochasticSlowKPeriod +
+		indicator.config.StochasticSlowDPeriod
+	if required > len(candles) {
+		return nil, nil, false
+	}
+
+	slowK, slowD := talib.Stoch(
+		GetHighPrices(candles),
+		GetLowPrices(candles),
+		GetClosePrices(candles),
+		indicator.config.StochasticFastKPeriod,
+		indicator.config.StochasticSlowKPeriod,
+		talib.SMA,
+		indicator.config.StochasticSlowDPeriod,
+		talib.SMA,
+	)
+
+	last := len(slowK) - 1
+
+	return slowK[last-1:], slowD[last-1:], true
+}
+
+func (indicator *StochasticIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *StochasticIndicator) Start() {
+}
+
+func (indicator *StochasticIndicator) Update() {
+}
+
+func (indicator *StochasticIndicator) Finish() {
+}
+
+func (indicator *StochasticIndicator) GetTraceValues() []TraceValue {
+	slowK, slowD, ok := indicator.calcValues()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "slow_k", Value: slowK[1]},
+		{Name: "slow_d", Value: slowD[1]},
+	}
+}
+
+// ---------------------------------------
+
+type VolumeSpikeIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewVolumeSpikeIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) VolumeSpikeIndicator {
+	return VolumeSpikeIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// Capitulation, the last candle volume is a multiple of the average one
+func (indicator *VolumeSpikeIndicator) HasSignal() bool {
+	avgVolume, ok := indicator.calcAvgVolume()
+	if !ok || avgVolume == 0 {
+		return false
+	}
+
+	return indicator.buffer.GetLastCandle().Volume >= avgVolume*indicator.config.VolumeSpikeMultiplier
+}
+
+// Average volume of the candles before the last one
+func (indicator *VolumeSpikeIndicator) calcAvgVolume() (float64, bool) {
+	candles := indicator.buffer.GetCandles()
+	count := len(candles)
+	if (indicator.config.VolumeSpikePeriod + 1) > count {
+		return 0, false
+	}
+
+	return GetAvg(GetVolumes(candles[count-indicator.config.VolumeSpikePeriod-1 : count-1])), true
+}
+
+func (indicator *VolumeSpikeIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *VolumeSpikeIndicator) Start() {
+}
+
+func (indicator *VolumeSpikeIndicator) Update() {
+}
+
+func (indicator *VolumeSpikeIndicator) Finish() {
+}
+
+func (indicator *VolumeSpikeIndicator) GetTraceValues() []TraceValue {
+	avgVolume, ok := indicator.calcAvgVolume()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "volume", Value: indicator.buffer.GetLastCandle().Volume},
+		{Name: "avg_volume", Value: avgVolume},
+	}
+}
+
+// ---------------------------------------
+
+type TakerImbalanceIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewTakerImbalanceIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) TakerImbalanceIndicator {
+	return TakerImbalanceIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// Taker sellers dominate the last candles
+func (indicator *TakerImbalanceIndicator) HasSignal() bool {
+	takerSellPercentage, ok := indicator.calcTakerSellPercentage()
+	if !ok {
+		return false
+	}
+
+	return takerSellPercentage >= indicator.config.TakerSellPercentage
+}
+
+func (indicator *TakerImbalanceIndicator) calcTakerSellPercentage() (float64, bool) {
+	candles := indicator.buffer.GetCandles()
+	count := len(candles)
+	if indicator.config.TakerImbalancePeriod > count {
+		return 0, false
+	}
+
+	volume := 0.0
+	takerBuyVolume := 0.0
+	for _, candle := range candles[count-indicator.config.TakerImbalancePeriod:] {
+		volume += candle.Volume
+		takerBuyVolume += candle.TakerBuyBaseAssetVolume
+	}
+
+	if volume == 0 {
+		return 0, false
+	}
+
+	return ((volume - takerBuyVolume) * 100) / volume, true
+}
+
+func (indicator *TakerImbalanceIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *TakerImbalanceIndicator) Start() {
+}
+
+func (indicator *TakerImbalanceIndicator) Update() {
+}
+
+func (indicator *TakerImbalanceIndicator) Finish() {
+}
+
+func (indicator *TakerImbalanceIndicator) GetTraceValues() []TraceValue {
+	takerSellPercentage, ok := indicator.calcTakerSellPercentage()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{{Name: "taker_sell_percentage", Value: takerSellPercentage}}
+}
+
+// ---------------------------------------
+
+type VwapDeviationIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewVwapDeviationIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) VwapDeviationIndicator {
+	return VwapDeviationIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// The price is far enough below the rolling VWAP
+func (indicator *VwapDeviationIndicator) HasSignal() bool {
+	vwap, ok := indicator.calcVwap()
+	if !ok {
+		return false
+	}
+
+	deviationPercentage := -1 * CalcGrowth(vwap, indicator.buffer.GetLastCandleClosePrice())
+
+	return deviationPercentage >= indicator.config.VwapDeviationPercentage
+}
+
+func (indicator *VwapDeviationIndicator) calcVwap() (float64, bool) {
+	candles := indicator.buffer.GetCandles()
+	count := len(candles)
+	if indicator.config.VwapPeriod > count {
+		return 0, false
+	}
+
+	volume := 0.0
+	quoteVolume := 0.0
+	for _, candle := range candles[count-indicator.config.VwapPeriod:] {
+		volume += candle.Volume
+		quoteVolume += candle.QuoteAssetVolume
+	}
+
+	if volume == 0 {
+		return 0, false
+	}
+
+	return quoteVolume / volume, true
+}
+
+func (indicator *VwapDeviationIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *VwapDeviationIndicator) Start() {
+}
+
+func (indicator *VwapDeviationIndicator) Update() {
+}
+
+func (indicator *VwapDeviationIndicator) Finish() {
+}
+
+func (indicator *VwapDeviationIndicator) GetTraceValues() []TraceValue {
+	vwap, ok := indicator.calcVwap()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "vwap", Value: vwap},
+		{Name: "deviation_percentage", Value: -1 * CalcGrowth(vwap, indicator.buffer.GetLastCandleClosePrice())},
+	}
+}
+
+// ---------------------------------------
+
+type ObvTrendIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewObvTrendIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) ObvTrendIndicator {
+	return ObvTrendIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+// On-balance volume has grown over the period, buyers accumulate
+func (indicator *ObvTrendIndicator) HasSignal() bool {
+	firstObv, lastObv, ok := indicator.calcObv()
+	if !ok {
+		return false
+	}
+
+	return lastObv > firstObv
+}
+
+// On-balance volume at the start of the period and on the last candle
+func (indicator *ObvTrendIndicator) calcObv() (float64, float64, bool) {
+	candles := indicator.buffer.GetCandles()
+	count := len(candles)
+	if (indicator.config.ObvPeriod + 1) > count {
+		return 0, 0, false
+	}
+
+	obv := talib.Obv(GetClosePrices(candles), GetVolumes(candles))
+	last := len(obv) - 1
+
+	return obv[last-indicator.config.ObvPeriod], obv[last], true
+}
+
+func (indicator *ObvTrendIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *ObvTrendIndicator) Start() {
+}
+
+func (indicator *ObvTrendIndicator) Update() {
+}
+
+func (indicator *ObvTrendIndicator) Finish() {
+}
+
+func (indicator *ObvTrendIndicator) GetTraceValues() []TraceValue {
+	firstObv, lastObv, ok := indicator.calcObv()
+	if !ok {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "obv", Value: lastObv},
+		{Name: "obv_change", Value: lastObv - firstObv},
+	}
+}
+
+// ---------------------------------------
+
+type HigherTrendIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+	ema    *StreamingEma
+}
+
+func NewHigherTrendIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) HigherTrendIndicator {
+	timeframe := buffer.GetTimeframe(HIGHER_TIMEFRAMES[config.HigherTrendTimeframe])
+
+	return HigherTrendIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+		ema:    timeframe.GetEma(config.HigherTrendEmaPeriod, config.HigherTrendSlopeCandles+1),
+	}
+}
+
+// EMA of the higher timeframe is rising, so the dip is bought only in the uptrend
+func (indicator *HigherTrendIndicator) HasSignal() bool {
+	values := indicator.ema.GetValues()
+	if (indicator.config.HigherTrendSlopeCandles + 1) > len(values) {
+		return false
+	}
+
+	return values[len(values)-1] > values[0]
+}
+
+func (indicator *HigherTrendIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *HigherTrendIndicator) Start() {
+}
+
+func (indicator *HigherTrendIndicator) Update() {
+}
+
+func (indicator *HigherTrendIndicator) Finish() {
+}
+
+func (indicator *HigherTrendIndicator) GetTraceValues() []TraceValue {
+	values := indicator.ema.GetValues()
+	if 0 == len(values) {
+		return []TraceValue{}
+	}
+
+	return []TraceValue{
+		{Name: "first_ema", Value: values[0]},
+		{Name: "last_ema", Value: values[len(values)-1]},
+	}
+}
+
+// ---------------------------------------
+
+type RegimeIndicator struct {
+	config     *Config
+	buffer     *Buffer
+	db         *Database
+	classifier RegimeClassifier
+}
+
+func NewRegimeIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) RegimeIndicator {
+	return RegimeIndicator{
+		config:     config,
+		buffer:     buffer,
+		db:         db,
+		classifier: NewRegimeClassifier(config, buffer),
+	}
+}
+
+// Entries only in the regimes of the RegimeMask, bit N is the regime N
+func (indicator *RegimeIndicator) HasSignal() bool {
+	regime := indicator.classifier.Classify()
+	if regime == RegimeUnknown {
+		return false
+	}
+
+	return indicator.config.RegimeMask&(1<<regime) != 0
+}
+
+func (indicator *RegimeIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *RegimeIndicator) Start() {
+}
+
+func (indicator *RegimeIndicator) Update() {
+}
+
+func (indicator *RegimeIndicator) Finish() {
+}
+
+func (indicator *RegimeIndicator) GetTraceValues() []TraceValue {
+	adx, slope, volatility, _ := indicator.classifier.calcValues()
+
+	return []TraceValue{
+		{Name: "regime", Value: float64(indicator.classifier.Classify())},
+		{Name: "adx", Value: adx},
+		{Name: "slope_percentage", Value: slope},
+		{Name: "volatility_percentage", Value: volatility},
+	}
+}
+
+// ---------------------------------------
+
+// Pauses new entries for CircuitBreakerCooldownMinutes after a streak of losing sells, liquidations
+// or a drawdown of the realized profit. Only sells after the last reset are counted, the counters
+// are kept up to date with the sells since the last counted one.
+type CircuitBreakerIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+
+	isTripped    bool
+	trippedUntil time.Time
+	lastResetAt  time.Time
+
+	lastSellId        int64
+	lossesCount       int
+	liquidationsCount int
+	profit            float64
+	peakProfit        float64
+	drawdown          float64
+}
+
+func NewCircuitBreakerIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) CircuitBreakerIndicator {
+	return CircuitBreakerIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+func (indicator *CircuitBreakerIndicator) HasSignal() bool {
+	return !indicator.isTripped
+}
+
+func (indicator *CircuitBreakerIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *CircuitBreakerIndicator) Start() {
+}
+
+func (indicator *CircuitBreakerIndicator) Update() {
+	candle := indicator.buffer.GetLastCandle()
+	indicator.countNewSells()
+
+	if indicator.isTripped {
+		if candle.CloseTime.Before(indicator.trippedUntil) {
+			return
+		}
+
+		indicator.isTripped = false
+		Log(fmt.Sprintf("CircuitBreaker__RESET: %s", FormatTime(candle.CloseTime)))
+	}
+
+	reason := ""
+	if indicator.lossesCount >= indicator.config.CircuitBreakerLossesCount {
+		reason = fmt.Sprintf("%d losses in a row", indicator.lossesCount)
+	} else if indicator.liquidationsCount >= indicator.config.CircuitBreakerLiquidationsCount {
+		reason = fmt.Sprintf("%d liquidations", indicator.liquidationsCount)
+	} else if indicator.drawdown >= CalcValuePercentage(BALANCE_MONEY, indicator.config.CircuitBreakerDrawdownPercentage) {
+		reason = fmt.Sprintf("drawdown %f", indicator.drawdown)
+	}
+
+	if reason == "" {
+		return
+	}
+
+	indicator.isTripped = true
+	indicator.lastResetAt = candle.CloseTime
+	indicator.trippedUntil = candle.CloseTime.Add(time.Duration(indicator.config.CircuitBreakerCooldownMinutes) * time.Minute)
+	indicator.resetCounters()
+
+	Log(fmt.Sprintf(
+		"CircuitBreaker__TRIPPED: %s\nReason: %s\nUntil: %s",
+		FormatTime(candle.CloseTime),
+		reason,
+		FormatTime(indicator.trippedUntil),
+	))
+}
+
+func (indicator *CircuitBreakerIndicator) Finish() {
+}
+
+// Losses in a row at the end, liquidations and drawdown from the profit peak, all after the last reset
+func (indicator *CircuitBreakerIndicator) countNewSells() {
+	for _, sell := range indicator.db.FetchSellsAfterId(indicator.lastSellId) {
+		indicator.lastSellId = sell.Id
+		if !sell.CreatedAt.After(indicator.lastResetAt) {
+			continue
+		}
+
+		sellProfit := indicator.calcSellProfit(sell)
+		if sellProfit < 0 {
+			indicator.lossesCount++
+		} else {
+			indicator.lossesCount = 0
+		}
+
+		if ENABLE_FUTURES && sell.Revenue == 0 {
+			indicator.liquidationsCount++
+		}
+
+		indicator.profit += sellProfit
+		indicator.peakProfit = math.Max(indicator.peakProfit, indicator.profit)
+		indicator.drawdown = math.Max(indicator.drawdown, indicator.peakProfit-indicator.profit)
+	}
+}
+
+func (indicator *CircuitBreakerIndicator) resetCounters() {
+	indicator.lossesCount = 0
+	indicator.liquidationsCount = 0
+	indicator.profit = 0
+	indicator.peakProfit = 0
+	indicator.drawdown = 0
+}
+
+// Futures revenue is the position value for the profitable sells, the loss for the negative ones
+// and 0 for the liquidations
+func (indicator *CircuitBreakerIndicator) calcSellProfit(sell Sell) float64 {
+	if !ENABLE_FUTURES {
+		return sell.Revenue - sell.MoneyAmount
+	}
+
+	if sell.Revenue == 0 {
+		return -sell.MoneyAmount
+	}
+
+	if sell.Revenue < 0 {
+		return sell.Revenue
+	}
+
+	return sell.Revenue - sell.MoneyAmount*float64(indicator.config.Leverage)
+}
+
+type circuitBreakerState struct {
+	IsTripped    bool  `json:"is_tripped"`
+	TrippedUntil int64 `json:"tripped_until"`
+	LastResetAt  int64 `json:"last_reset_at"`
+}
+
+func (indicator *CircuitBreakerIndicator) GetStateName() string {
+	return "CircuitBreaker"
+}
+
+func (indicator *CircuitBreakerIndicator) GetStateVersion() int {
+	return 1
+}
+
+func (indicator *CircuitBreakerIndicator) SaveState() string {
+	return marshalIndicatorState(circuitBreakerState{
+		IsTripped:    indicator.isTripped,
+		TrippedUntil: indicator.trippedUntil.UnixMilli(),
+		LastResetAt:  indicator.lastResetAt.UnixMilli(),
+	})
+}
+
+func (indicator *CircuitBreakerIndicator) RestoreState(encoded string) {
+	state := circuitBreakerState{}
+	unmarshalIndicatorState(encoded, &state)
+
+	indicator.isTripped = state.IsTripped
+	indicator.trippedUntil = ParseMilliTimestamp(state.TrippedUntil)
+	indicator.lastResetAt = ParseMilliTimestamp(state.LastResetAt)
+
+	// The counters are not saved, the next update counts the sells after the reset again
+	indicator.lastSellId = 0
+	indicator.resetCounters()
+}
+
+func (indicator *CircuitBreakerIndicator) GetTraceValues() []TraceValue {
+	return []TraceValue{
+		{Name: "is_tripped", Value: boolToTraceValue(indicator.isTripped)},
+		{Name: "losses_count", Value: float64(indicator.lossesCount)},
+		{Name: "liquidations_count", Value: float64(indicator.liquidationsCount)},
+		{Name: "drawdown", Value: indicator.drawdown},
+	}
+}
+
+// ---------------------------------------
+
+// Entries only in the UTC hours window (it may wrap midnight), on the weekdays of the mask
+// (bit N is time.Weekday N) and outside the blackouts of the calendar file
+type TradingWindowIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewTradingWindowIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) TradingWindowIndicator {
+	return TradingWindowIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+func (indicator *TradingWindowIndicator) HasSignal() bool {
+	closeTime := indicator.buffer.GetLastCandle().CloseTime.UTC()
+
+	hoursFromStart := (closeTime.Hour() - indicator.config.TradingWindowStartHour + 24) % 24
+	if hoursFromStart >= indicator.config.TradingWindowHoursCount {
+		return false
+	}
+
+	if indicator.config.TradingWeekdaysMask&(1<<closeTime.Weekday()) == 0 {
+		return false
+	}
+
+	return !IsInBlackout(closeTime)
+}
+
+func (indicator *TradingWindowIndicator) IsStarted() bool {
+	return true
+}
+
+func (indicator *TradingWindowIndicator) Start() {
+}
+
+func (indicator *TradingWindowIndicator) Update() {
+}
+
+func (indicator *TradingWindowIndicator) Finish() {
+}
+
+func (indicator *TradingWindowIndicator) GetTraceValues() []TraceValue {
+	closeTime := indicator.buffer.GetLastCandle().CloseTime.UTC()
+
+	return []TraceValue{
+		{Name: "hours_from_start", Value: float64((closeTime.Hour() - indicator.config.TradingWindowStartHour + 24) % 24)},
+		{Name: "weekday", Value: float64(closeTime.Weekday())},
+		{Name: "blackout", Value: boolToTraceValue(IsInBlackout(closeTime))},
+	}
+}
@@ -0,0 +1,687 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+type buyIndicatorTest struct {
+	name     string
+	config   func(config *Config)
+	candles  []Candle
+	setup    func(db *Database, buffer *Buffer)
+	expected []bool
+}
+
+func runBuyIndicatorTests(
+	t *testing.T,
+	constructor func(config *Config, buffer *Buffer, db *Database) BuyIndicator,
+	tests []buyIndicatorTest,
+) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := newTestConfig()
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			buffer := newTestBuffer(&config)
+			db := newTestDatabase(t, &config)
+			if test.setup != nil {
+				test.setup(db, buffer)
+			}
+
+			indicator := constructor(&config, buffer, db)
+			assertSignals(t, test.expected, runTestBuyIndicator(buffer, indicator, test.candles))
+		})
+	}
+}
+
+func buyIndicatorConstructor(name string) func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+	return buyIndicatorConstructors[name]
+}
+
+func TestBuyIndicatorsAreTraceable(t *testing.T) {
+	random, _ := NewRand(1)
+
+	for name, constructor := range buyIndicatorConstructors {
+		config := InitBotConfig(random, GetDefaultSearchSpace())
+		buffer := newTestBuffer(&config)
+		indicator := constructor(&config, buffer, newTestDatabase(t, &config))
+
+		traceable, ok := indicator.(TraceableIndicator)
+		if !ok {
+			t.Errorf("%s has no trace values", name)
+			continue
+		}
+
+		runTestBuyIndicator(buffer, indicator, newTestPriceCandles(100, 99, 98))
+		traceable.GetTraceValues()
+	}
+}
+
+func TestBackTrailingBuyIndicator(t *testing.T) {
+	runBuyIndicatorTests(t, buyIndicatorConstructor("BackTrailing"), []buyIndicatorTest{
+		{
+			name:     "buys the rebound from the bottom",
+			config:   func(config *Config) { config.TrailingTopPercentage = 1 },
+			candles:  newTestPriceCandles(100, 99, 98, 99.5, 99.5),
+			expected: []bool{false, false, false, true, false},
+		},
+		{
+			name:     "keeps falling",
+			config:   func(config *Config) { config.TrailingTopPercentage = 1 },
+			candles:  newTestPriceCandles(100, 99, 98, 97, 96),
+			expected: []bool{false, false, false, false, false},
+		},
+		{
+			name: "holds the signal for the update times",
+			config: func(config *Config) {
+				config.TrailingTopPercentage = 1
+				config.TrailingUpdateTimesBeforeFinish = 2
+			},
+			candles:  newTestPriceCandles(100, 98, 99.5, 99, 98, 98),
+			expected: []bool{false, false, true, true, true, false},
+		},
+	})
+}
+
+func TestBuysCountIndicator(t *testing.T) {
+	addBuys := func(count int) func(db *Database, buffer *Buffer) {
+		return func(db *Database, buffer *Buffer) {
+			for i := 0; i < count; i++ {
+				db.AddBuy(CANDLE_SYMBOL, 1, 100, 101, testStartTime, 100)
+			}
+		}
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("BuysCount"), []buyIndicatorTest{
+		{
+			name:     "below the limit",
+			candles:  newTestPriceCandles(100),
+			setup:    addBuys(UNSOLD_BUYS_COUNT - 1),
+			expected: []bool{true},
+		},
+		{
+			name:     "at the limit",
+			candles:  newTestPriceCandles(100),
+			setup:    addBuys(UNSOLD_BUYS_COUNT),
+			expected: []bool{false},
+		},
+	})
+}
+
+func TestWaitForPeriodIndicator(t *testing.T) {
+	runBuyIndicatorTests(t, buyIndicatorConstructor("WaitForPeriod"), []buyIndicatorTest{
+		{
+			name:   "waits after the last buy",
+			config: func(config *Config) { config.WaitAfterLastBuyPeriod = 2 },
+			setup: func(db *Database, buffer *Buffer) {
+				db.AddBuy(CANDLE_SYMBOL, 1, 100, 101, testStartTime.Add(time.Minute-time.Millisecond), 100)
+			},
+			candles:  newTestPriceCandles(100, 100, 100, 100),
+			expected: []bool{false, false, true, true},
+		},
+		{
+			name:     "no buys",
+			config:   func(config *Config) { config.WaitAfterLastBuyPeriod = 2 },
+			candles:  newTestPriceCandles(100),
+			expected: []bool{true},
+		},
+	})
+}
+
+func TestBigFallIndicator(t *testing.T) {
+	runBuyIndicatorTests(t, buyIndicatorConstructor("BigFall"), []buyIndicatorTest{
+		{
+			name:     "fall in the window",
+			candles:  newTestPriceCandles(100, 100, 100, 100, 100, 97),
+			expected: []bool{false, false, false, false, false, true},
+		},
+		{
+			name:     "fall below the percentage",
+			candles:  newTestPriceCandles(100, 100, 100, 100, 100, 99),
+			expected: []bool{false, false, false, false, false, false},
+		},
+		{
+			name:     "fall leaves the window",
+			candles:  newTestPriceCandles(100, 97, 97, 97, 97, 97, 97),
+			expected: []bool{false, false, false, false, false, true, false},
+		},
+		{
+			name:     "growth",
+			candles:  newTestPriceCandles(100, 101, 102, 103, 104, 105),
+			expected: []bool{false, false, false, false, false, false},
+		},
+		{
+			name:     "smoothed fall",
+			config:   func(config *Config) { config.BigFallSmoothPeriod = 2 },
+			candles:  newTestPriceCandles(100, 100, 100, 100, 100, 97),
+			expected: []bool{false, false, false, false, false, false},
+		},
+		{
+			name:     "smoothed fall is big enough",
+			config:   func(config *Config) { config.BigFallSmoothPeriod = 2 },
+			candles:  newTestPriceCandles(100, 100, 100, 100, 100, 100, 94),
+			expected: []bool{false, false, false, false, false, false, true},
+		},
+		{
+			name:     "lookback spans the desired price candles",
+			config:   func(config *Config) { config.DesiredPriceCandles = 20 },
+			candles:  newTestPriceCandles(100, 97, 97, 97, 97, 97, 97, 97),
+			expected: []bool{false, false, false, false, false, true, true, true},
+		},
+		{
+			name:     "lookback does not grow with the newer indicators",
+			config:   func(config *Config) { config.RsiPeriod = 20 },
+			candles:  newTestPriceCandles(100, 97, 97, 97, 97, 97, 97, 97),
+			expected: []bool{false, false, false, false, false, true, false, false},
+		},
+	})
+}
+
+func TestGradientDescentIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.GradientDescentCandles = 5
+		config.GradientDescentPeriod = 1
+		config.GradientDescentGradient = 0.5
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("GradientDescent"), []buyIndicatorTest{
+		{
+			name:     "flat bottom",
+			config:   config,
+			candles:  newTestPriceCandles(100, 100, 100, 100, 100, 101),
+			expected: []bool{false, false, false, false, false, true},
+		},
+		{
+			name:     "steep fall",
+			config:   config,
+			candles:  newTestPriceCandles(100, 98, 96, 94, 92, 90),
+			expected: []bool{false, false, false, false, false, false},
+		},
+		{
+			name:     "steep growth",
+			config:   config,
+			candles:  newTestPriceCandles(90, 92, 94, 96, 98, 100),
+			expected: []bool{false, false, false, false, false, false},
+		},
+	})
+}
+
+func TestLessThanPreviousBuyIndicator(t *testing.T) {
+	addBuy := func(isSold bool) func(db *Database, buffer *Buffer) {
+		return func(db *Database, buffer *Buffer) {
+			db.AddBuy(CANDLE_SYMBOL, 1, 100, 101, testStartTime, 100)
+			if isSold {
+				db.AddSell(CANDLE_SYMBOL, 1, 101, 101, 1, testStartTime)
+			}
+		}
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("LessThanPreviousBuy"), []buyIndicatorTest{
+		{
+			name:     "first buy",
+			candles:  newTestPriceCandles(120),
+			expected: []bool{true},
+		},
+		{
+			name:     "price of the unsold buy",
+			candles:  newTestPriceCandles(99, 100, 101),
+			setup:    addBuy(false),
+			expected: []bool{true, true, false},
+		},
+		{
+			name:     "all buys are sold",
+			candles:  newTestPriceCandles(90),
+			setup:    addBuy(true),
+			expected: []bool{false},
+		},
+		{
+			name: "DCA positions are averaged by the safety orders",
+			config: func(config *Config) {
+				config.DcaEnabled = 1
+				config.DcaSafetyOrdersCount = 2
+			},
+			candles:  newTestPriceCandles(120),
+			setup:    addBuy(false),
+			expected: []bool{true},
+		},
+	})
+}
+
+func TestRsiIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.RsiPeriod = 3
+		config.RsiOversold = 30
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("Rsi"), []buyIndicatorTest{
+		{
+			name:     "oversold",
+			config:   config,
+			candles:  newTestPriceCandles(100, 99, 98, 97, 96),
+			expected: []bool{false, false, false, true, true},
+		},
+		{
+			name:     "growth",
+			config:   config,
+			candles:  newTestPriceCandles(100, 101, 102, 103, 104),
+			expected: []bool{false, false, false, false, false},
+		},
+		{
+			name:     "rebound leaves the zone",
+			config:   config,
+			candles:  newTestPriceCandles(100, 99, 98, 97, 100),
+			expected: []bool{false, false, false, true, false},
+		},
+	})
+}
+
+func TestMacdIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.MacdFastPeriod = 2
+		config.MacdSlowPeriod = 3
+		config.MacdSignalPeriod = 2
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("Macd"), []buyIndicatorTest{
+		{
+			name:     "crosses the signal line from below",
+			config:   config,
+			candles:  newTestPriceCandles(100, 99, 98, 97, 96, 95, 99),
+			expected: []bool{false, false, false, false, false, false, true},
+		},
+		{
+			name:     "keeps falling",
+			config:   config,
+			candles:  newTestPriceCandles(100, 99, 98, 97, 96, 95, 94),
+			expected: []bool{false, false, false, false, false, false, false},
+		},
+	})
+}
+
+func TestBollingerIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.BollingerPeriod = 5
+		config.BollingerDeviation = 2
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("Bollinger"), []buyIndicatorTest{
+		{
+			name:   "touches the lower band",
+			config: config,
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 101, Low: 99, Close: 101},
+				{Open: 101, High: 101, Low: 99, Close: 99},
+				{Open: 99, High: 101, Low: 99, Close: 101},
+				{Open: 101, High: 101, Low: 99, Close: 99},
+				{Open: 99, High: 99, Low: 95, Close: 98},
+			}),
+			expected: []bool{false, false, false, false, true},
+		},
+		{
+			name:   "inside the bands",
+			config: config,
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 101, Low: 99, Close: 101},
+				{Open: 101, High: 101, Low: 99, Close: 99},
+				{Open: 99, High: 101, Low: 99, Close: 101},
+				{Open: 101, High: 101, Low: 99, Close: 99},
+				{Open: 99, High: 101, Low: 99, Close: 100},
+			}),
+			expected: []bool{false, false, false, false, false},
+		},
+	})
+}
+
+func TestStochasticIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.StochasticFastKPeriod = 3
+		config.StochasticSlowKPeriod = 1
+		config.StochasticSlowDPeriod = 2
+		config.StochasticOversold = 40
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("Stochastic"), []buyIndicatorTest{
+		{
+			name:   "%K crosses %D in the oversold zone",
+			config: config,
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 101, Low: 99, Close: 100},
+				{Open: 100, High: 100, Low: 97, Close: 97},
+				{Open: 97, High: 97, Low: 94, Close: 94},
+				{Open: 94, High: 94, Low: 91, Close: 91},
+				{Open: 91, High: 91, Low: 88, Close: 88},
+				{Open: 88, High: 91, Low: 88, Close: 90},
+			}),
+			expected: []bool{false, false, false, false, false, true},
+		},
+		{
+			name:   "keeps falling",
+			config: config,
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 101, Low: 99, Close: 100},
+				{Open: 100, High: 100, Low: 97, Close: 97},
+				{Open: 97, High: 97, Low: 94, Close: 94},
+				{Open: 94, High: 94, Low: 91, Close: 91},
+				{Open: 91, High: 91, Low: 88, Close: 88},
+				{Open: 88, High: 88, Low: 85, Close: 85},
+			}),
+			expected: []bool{false, false, false, false, false, false},
+		},
+	})
+}
+
+func TestVolumeSpikeIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.VolumeSpikePeriod = 3
+		config.VolumeSpikeMultiplier = 2
+	}
+	candles := func(volumes ...float64) []Candle {
+		var rows []testCandle
+		for _, volume := range volumes {
+			rows = append(rows, testCandle{Open: 100, High: 100, Low: 100, Close: 100, Volume: volume})
+		}
+
+		return newTestCandles(rows)
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("VolumeSpike"), []buyIndicatorTest{
+		{
+			name:     "spike",
+			config:   config,
+			candles:  candles(1, 1, 1, 2),
+			expected: []bool{false, false, false, true},
+		},
+		{
+			name:     "usual volume",
+			config:   config,
+			candles:  candles(1, 1, 1, 1.5),
+			expected: []bool{false, false, false, false},
+		},
+		{
+			name:     "no volume",
+			config:   config,
+			candles:  candles(0, 0, 0, 1),
+			expected: []bool{false, false, false, false},
+		},
+	})
+}
+
+func TestTakerImbalanceIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.TakerImbalancePeriod = 2
+		config.TakerSellPercentage = 60
+	}
+	candles := func(takerBuyVolumes ...float64) []Candle {
+		var rows []testCandle
+		for _, takerBuyVolume := range takerBuyVolumes {
+			rows = append(rows, testCandle{Open: 100, High: 100, Low: 100, Close: 100, Volume: 10, TakerBuy: takerBuyVolume})
+		}
+
+		return newTestCandles(rows)
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("TakerImbalance"), []buyIndicatorTest{
+		{
+			name:     "sellers dominate",
+			config:   config,
+			candles:  candles(5, 3, 6),
+			expected: []bool{false, true, false},
+		},
+		{
+			name:     "balanced",
+			config:   config,
+			candles:  candles(5, 5),
+			expected: []bool{false, false},
+		},
+	})
+}
+
+func TestVwapDeviationIndicator(t *testing.T) {
+	runBuyIndicatorTests(t, buyIndicatorConstructor("VwapDeviation"), []buyIndicatorTest{
+		{
+			name: "far below the VWAP",
+			config: func(config *Config) {
+				config.VwapPeriod = 3
+				config.VwapDeviationPercentage = 2
+			},
+			candles:  newTestPriceCandles(100, 100, 100, 95),
+			expected: []bool{false, false, false, true},
+		},
+		{
+			name: "near the VWAP",
+			config: func(config *Config) {
+				config.VwapPeriod = 3
+				config.VwapDeviationPercentage = 2
+			},
+			candles:  newTestPriceCandles(100, 100, 100, 99),
+			expected: []bool{false, false, false, false},
+		},
+	})
+}
+
+func TestObvTrendIndicator(t *testing.T) {
+	config := func(config *Config) { config.ObvPeriod = 2 }
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("ObvTrend"), []buyIndicatorTest{
+		{
+			name:     "accumulation",
+			config:   config,
+			candles:  newTestPriceCandles(100, 101, 102, 101),
+			expected: []bool{false, false, true, false},
+		},
+		{
+			name:     "distribution",
+			config:   config,
+			candles:  newTestPriceCandles(100, 99, 98, 97),
+			expected: []bool{false, false, false, false},
+		},
+	})
+}
+
+func TestHigherTrendIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.HigherTrendTimeframe = 0
+		config.HigherTrendEmaPeriod = 2
+		config.HigherTrendSlopeCandles = 1
+	}
+	// 4h candles from the one minute ones, the last candle closes the third 4h candle,
+	// that is the first one with two EMA values
+	candles := func(step float64) []Candle {
+		var prices []float64
+		for i := 0; i < 3*240; i++ {
+			prices = append(prices, 100+step*float64(i/240))
+		}
+
+		return newTestPriceCandles(prices...)
+	}
+	lastSignal := func(count int, signal bool) []bool {
+		signals := make([]bool, count)
+		signals[count-1] = signal
+
+		return signals
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("HigherTrend"), []buyIndicatorTest{
+		{
+			name:     "rising EMA",
+			config:   config,
+			candles:  candles(1),
+			expected: lastSignal(3*240, true),
+		},
+		{
+			name:     "falling EMA",
+			config:   config,
+			candles:  candles(-1),
+			expected: lastSignal(3*240, false),
+		},
+	})
+}
+
+func TestRegimeIndicator(t *testing.T) {
+	config := func(mask int) func(config *Config) {
+		return func(config *Config) {
+			config.RegimeAdxPeriod = 3
+			config.RegimeAdxThreshold = 20
+			config.RegimeMaPeriod = 3
+			config.RegimeSlopeCandles = 2
+			config.RegimeVolatilityPeriod = 5
+			config.RegimeVolatilityThreshold = 5
+			config.RegimeMask = mask
+		}
+	}
+	var rows []testCandle
+	for i := 0; i < 10; i++ {
+		price := 100 + float64(i)
+		rows = append(rows, testCandle{Open: price - 0.5, High: price + 0.2, Low: price - 0.7, Close: price})
+	}
+	uptrend := newTestCandles(rows)
+	// The ADX needs two periods of candles
+	signalsAfterWarmUp := func(signal bool) []bool {
+		signals := make([]bool, len(uptrend))
+		for i := 5; i < len(uptrend); i++ {
+			signals[i] = signal
+		}
+
+		return signals
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("Regime"), []buyIndicatorTest{
+		{
+			name:     "allowed regime",
+			config:   config(1 << RegimeTrendingUp),
+			candles:  uptrend,
+			expected: signalsAfterWarmUp(true),
+		},
+		{
+			name:     "masked regime",
+			config:   config(1<<RegimeRanging | 1<<RegimeTrendingDown),
+			candles:  uptrend,
+			expected: signalsAfterWarmUp(false),
+		},
+	})
+}
+
+func TestCircuitBreakerIndicator(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		config := func(config *Config) {
+			config.CircuitBreakerLossesCount = 2
+			config.CircuitBreakerLiquidationsCount = 10
+			config.CircuitBreakerDrawdownPercentage = 100
+			config.CircuitBreakerCooldownMinutes = 2
+		}
+		addSells := func(revenues ...float64) func(db *Database, buffer *Buffer) {
+			return func(db *Database, buffer *Buffer) {
+				for index, revenue := range revenues {
+					db.AddBuy(CANDLE_SYMBOL, 1, 100, 101, testStartTime, 100)
+					db.AddSell(CANDLE_SYMBOL, 1, 100, revenue, int64(index+1), testStartTime)
+				}
+			}
+		}
+
+		runBuyIndicatorTests(t, buyIndicatorConstructor("CircuitBreaker"), []buyIndicatorTest{
+			{
+				name:     "losses in a row trip it until the cooldown",
+				config:   config,
+				setup:    addSells(-10, -10),
+				candles:  newTestPriceCandles(100, 100, 100, 100),
+				expected: []bool{false, false, true, true},
+			},
+			{
+				name:     "profit breaks the streak",
+				config:   config,
+				setup:    addSells(-10, 2000, -10),
+				candles:  newTestPriceCandles(100),
+				expected: []bool{true},
+			},
+		})
+	})
+}
+
+func TestCircuitBreakerCountsSellsBetweenCandles(t *testing.T) {
+	runTestModes(t, func(t *testing.T) {
+		config := newTestConfig()
+		config.CircuitBreakerLossesCount = 2
+		config.CircuitBreakerLiquidationsCount = 10
+		config.CircuitBreakerDrawdownPercentage = 100
+		config.CircuitBreakerCooldownMinutes = 2
+
+		buffer := newTestBuffer(&config)
+		db := newTestDatabase(t, &config)
+		indicator := NewCircuitBreakerIndicator(&config, buffer, db)
+		addLoss := func(candle Candle) {
+			buyId, _ := db.AddBuy(CANDLE_SYMBOL, 1, 100, 101, candle.CloseTime, 100).LastInsertId()
+			db.AddSell(CANDLE_SYMBOL, 1, 100, -10, buyId, candle.CloseTime)
+		}
+
+		var signals []bool
+		for index, candle := range newTestPriceCandles(100, 100, 100, 100, 100) {
+			buffer.AddCandle(candle)
+			indicator.Update()
+			signals = append(signals, indicator.HasSignal())
+
+			if index == 0 || index == 1 {
+				addLoss(candle)
+			}
+		}
+
+		assertSignals(t, []bool{true, true, false, false, true}, signals)
+		assertFloat(t, "losses after the reset", 0, float64(indicator.lossesCount))
+
+		restored := NewCircuitBreakerIndicator(&config, buffer, db)
+		restored.RestoreState(indicator.SaveState())
+		restored.Update()
+		assertFloat(t, "losses after the restore", 0, float64(restored.lossesCount))
+	})
+}
+
+func TestTradingWindowIndicator(t *testing.T) {
+	var prices []float64
+	for i := 0; i < 121; i++ {
+		prices = append(prices, 100)
+	}
+	// Candles of 00:00, 01:00 and 02:00 on Monday
+	signals := func(first, second, third bool) []bool {
+		result := make([]bool, len(prices))
+		result[0] = first
+		result[60] = second
+		result[120] = third
+		for i := 1; i < 60; i++ {
+			result[i] = first
+		}
+		for i := 61; i < 120; i++ {
+			result[i] = second
+		}
+
+		return result
+	}
+
+	runBuyIndicatorTests(t, buyIndicatorConstructor("TradingWindow"), []buyIndicatorTest{
+		{
+			name: "one hour window",
+			config: func(config *Config) {
+				config.TradingWindowStartHour = 1
+				config.TradingWindowHoursCount = 1
+			},
+			candles:  newTestPriceCandles(prices...),
+			expected: signals(false, true, false),
+		},
+		{
+			name: "window wraps midnight",
+			config: func(config *Config) {
+				config.TradingWindowStartHour = 23
+				config.TradingWindowHoursCount = 2
+			},
+			candles:  newTestPriceCandles(prices...),
+			expected: signals(true, false, false),
+		},
+		{
+			name:     "weekday is masked",
+			config:   func(config *Config) { config.TradingWeekdaysMask = 1<<7 - 1 - 1<<time.Monday },
+			candles:  newTestPriceCandles(prices...),
+			expected: signals(false, false, false),
+		},
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Blackout date ranges, no entries from the start to the end. The CSV rows are
+// "from,to[,comment]" in UTC with DATE_TIME_LAYOUT, a missing file means no blackouts.
+type Blackout struct {
+	From    time.Time
+	To      time.Time
+	Comment string
+}
+
+var blackouts []Blackout
+var blackoutsOnce sync.Once
+
+func GetBlackouts() []Blackout {
+	blackoutsOnce.Do(func() {
+		blackouts = LoadBlackouts(TRADING_BLACKOUTS_FILE_NAME)
+	})
+
+	return blackouts
+}
+
+func LoadBlackouts(fileName string) []Blackout {
+	var result []Blackout
+	if !FileExists(fileName) {
+		return result
+	}
+
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return result
+		}
+
+		if err != nil {
+			panic(err)
+		}
+
+		if len(record) < 2 {
+			panic(fmt.Sprintf("Invalid blackout in %s: %v", fileName, record))
+		}
+
+		from, fromErr := time.Parse(DATE_TIME_LAYOUT, record[0])
+		to, toErr := time.Parse(DATE_TIME_LAYOUT, record[1])
+		if fromErr != nil || toErr != nil {
+			panic(fmt.Sprintf("Invalid blackout dates in %s: %v", fileName, record))
+		}
+
+		blackout := Blackout{From: from, To: to}
+		if len(record) > 2 {
+			blackout.Comment = record[2]
+		}
+
+		result = append(result, blackout)
+	}
+}
+
+func IsInBlackout(candleTime time.Time) bool {
+	for _, blackout := range GetBlackouts() {
+		if !candleTime.Before(blackout.From) && candleTime.Before(blackout.To) {
+			return true
+		}
+	}
+
+	return false
+}
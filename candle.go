@@ -4,6 +4,7 @@ import (
 	"github.com/adshao/go-binance/v2"
 	"github.com/adshao/go-binance/v2/futures"
 	"strconv"
+	"time"
 )
 
 const OPEN_TIME = 0
@@ -21,8 +22,8 @@ const IGNORE = 11
 
 type Candle struct {
 	Symbol                   string
-	OpenTime                 string
-	CloseTime                string
+	OpenTime                 time.Time
+	CloseTime                time.Time
 	OpenPrice                float64
 	HighPrice                float64
 	LowPrice                 float64
@@ -47,30 +48,36 @@ func WebSocketCandleToKlineCandle(wsKline binance.WsKline) Candle {
 	highPrice, highPriceErr := strconv.ParseFloat(wsKline.High, 64)
 	lowPrice, lowPriceErr := strconv.ParseFloat(wsKline.Low, 64)
 	volume, volumeErr := strconv.ParseFloat(wsKline.Volume, 64)
+	quoteVolume, quoteVolumeErr := strconv.ParseFloat(wsKline.QuoteVolume, 64)
+	takerBuyVolume, takerBuyVolumeErr := strconv.ParseFloat(wsKline.ActiveBuyVolume, 64)
+	takerBuyQuoteVolume, takerBuyQuoteVolumeErr := strconv.ParseFloat(wsKline.ActiveBuyQuoteVolume, 64)
 
 	if openPriceErr != nil ||
 		closePriceErr != nil ||
 		highPriceErr != nil ||
 		lowPriceErr != nil ||
-		volumeErr != nil {
+		volumeErr != nil ||
+		quoteVolumeErr != nil ||
+		takerBuyVolumeErr != nil ||
+		takerBuyQuoteVolumeErr != nil {
 
 		panic("Can not convert Websocket candle.")
 	}
 
 	return Candle{
-		Symbol:     wsKline.Symbol,
-		OpenTime:   FormatTimestamp(wsKline.StartTime),
-		CloseTime:  FormatTimestamp(wsKline.EndTime),
-		OpenPrice:  openPrice,
-		HighPrice:  highPrice,
-		LowPrice:   lowPrice,
-		ClosePrice: closePrice,
-		Volume:     volume,
-		//QuoteAssetVolume:         wsKline.QuoteVolume,
-		NumberOfTrades: wsKline.TradeNum,
-		//TakerBuyBaseAssetVolume:  wsKline.ActiveBuyVolume,
-		//TakerBuyQuoteAssetVolume: wsKline.ActiveBuyQuoteVolume,
-		IsClosed: wsKline.IsFinal,
+		Symbol:                   wsKline.Symbol,
+		OpenTime:                 ParseMilliTimestamp(wsKline.StartTime),
+		CloseTime:                ParseMilliTimestamp(wsKline.EndTime),
+		OpenPrice:                openPrice,
+		HighPrice:                highPrice,
+		LowPrice:                 lowPrice,
+		ClosePrice:               closePrice,
+		Volume:                   volume,
+		QuoteAssetVolume:         quoteVolume,
+		NumberOfTrades:           wsKline.TradeNum,
+		TakerBuyBaseAssetVolume:  takerBuyVolume,
+		TakerBuyQuoteAssetVolume: takerBuyQuoteVolume,
+		IsClosed:                 wsKline.IsFinal,
 	}
 }
 
@@ -80,30 +87,36 @@ func WebSocketCandleToKlineCandleFutures(wsKline futures.WsKline) Candle {
 	highPrice, highPriceErr := strconv.ParseFloat(wsKline.High, 64)
 	lowPrice, lowPriceErr := strconv.ParseFloat(wsKline.Low, 64)
 	volume, volumeErr := strconv.ParseFloat(wsKline.Volume, 64)
+	quoteVolume, quoteVolumeErr := strconv.ParseFloat(wsKline.QuoteVolume, 64)
+	takerBuyVolume, takerBuyVolumeErr := strconv.ParseFloat(wsKline.ActiveBuyVolume, 64)
+	takerBuyQuoteVolume, takerBuyQuoteVolumeErr := strconv.ParseFloat(wsKline.ActiveBuyQuoteVolume, 64)
 
 	if openPriceErr != nil ||
 		closePriceErr != nil ||
 		highPriceErr != nil ||
 		lowPriceErr != nil ||
-		volumeErr != nil {
+		volumeErr != nil ||
+		quoteVolumeErr != nil ||
+		takerBuyVolumeErr != nil ||
+		takerBuyQuoteVolumeErr != nil {
 
 		panic("Can not convert Websocket candle.")
 	}
 
 	return Candle{
-		Symbol:     wsKline.Symbol,
-		OpenTime:   FormatTimestamp(wsKline.StartTime),
-		CloseTime:  FormatTimestamp(wsKline.EndTime),
-		OpenPrice:  openPrice,
-		HighPrice:  highPrice,
-		LowPrice:   lowPrice,
-		ClosePrice: closePrice,
-		Volume:     volume,
-		//QuoteAssetVolume:         wsKline.QuoteVolume,
-		NumberOfTrades: wsKline.TradeNum,
-		//TakerBuyBaseAssetVolume:  wsKline.ActiveBuyVolume,
-		//TakerBuyQuoteAssetVolume: wsKline.ActiveBuyQuoteVolume,
-		IsClosed: wsKline.IsFinal,
+		Symbol:                   wsKline.Symbol,
+		OpenTime:                 ParseMilliTimestamp(wsKline.StartTime),
+		CloseTime:                ParseMilliTimestamp(wsKline.EndTime),
+		OpenPrice:                openPrice,
+		HighPrice:                highPrice,
+		LowPrice:                 lowPrice,
+		ClosePrice:               closePrice,
+		Volume:                   volume,
+		QuoteAssetVolume:         quoteVolume,
+		NumberOfTrades:           wsKline.TradeNum,
+		TakerBuyBaseAssetVolume:  takerBuyVolume,
+		TakerBuyQuoteAssetVolume: takerBuyQuoteVolume,
+		IsClosed:                 wsKline.IsFinal,
 	}
 }
 
@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type CandleSource interface {
+	Next() (Candle, bool)
+	Close()
+}
+
+type CandleSourceFactory func() CandleSource
+
+func (bot *Bot) Run(source CandleSource) {
+	defer source.Close()
+
+	for {
+		candle, ok := source.Next()
+		if !ok {
+			return
+		}
+
+		bot.DoStuff(candle)
+	}
+}
+
+func CollectCandles(source CandleSource) []Candle {
+	var candles []Candle
+
+	defer source.Close()
+	for {
+		candle, ok := source.Next()
+		if !ok {
+			return candles
+		}
+
+		candles = append(candles, candle)
+	}
+}
+
+func CountCandles(source CandleSource) int {
+	count := 0
+
+	defer source.Close()
+	for {
+		if _, ok := source.Next(); !ok {
+			return count
+		}
+
+		count++
+	}
+}
+
+// --------------------------------
+
+type SliceCandleSource struct {
+	candles []Candle
+	index   int
+}
+
+func NewSliceCandleSource(candles []Candle) SliceCandleSource {
+	return SliceCandleSource{candles: candles}
+}
+
+func (source *SliceCandleSource) Next() (Candle, bool) {
+	if source.index >= len(source.candles) {
+		return Candle{}, false
+	}
+
+	candle := source.candles[source.index]
+	source.index++
+
+	return candle, true
+}
+
+func (source *SliceCandleSource) Close() {
+}
+
+// --------------------------------
+
+// Candles from start until end of another source, like a slice of it, without keeping them in memory
+type WindowCandleSource struct {
+	source CandleSource
+	start  int
+	end    int
+	index  int
+}
+
+func NewWindowCandleSource(source CandleSource, start int, end int) WindowCandleSource {
+	return WindowCandleSource{source: source, start: start, end: end}
+}
+
+func (source *WindowCandleSource) Next() (Candle, bool) {
+	for source.index < source.end {
+		candle, ok := source.source.Next()
+		if !ok {
+			return Candle{}, false
+		}
+
+		source.index++
+		if source.index > source.start {
+			return candle, true
+		}
+	}
+
+	return Candle{}, false
+}
+
+func (source *WindowCandleSource) Close() {
+	source.source.Close()
+}
+
+// --------------------------------
+
+type CsvCandleSource struct {
+	symbol  string
+	file    *os.File
+	reader  *csv.Reader
+	isFirst bool
+}
+
+func NewCsvCandleSource(fileName, symbol string) CsvCandleSource {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+
+	reader := csv.NewReader(bufio.NewReader(file))
+	reader.ReuseRecord = true
+
+	return CsvCandleSource{
+		symbol:  symbol,
+		file:    file,
+		reader:  reader,
+		isFirst: true,
+	}
+}
+
+func (source *CsvCandleSource) Next() (Candle, bool) {
+	record, ok := source.nextRecord()
+	if !ok {
+		return Candle{}, false
+	}
+
+	return CsvRecordToCandle(record, source.symbol), true
+}
+
+func (source *CsvCandleSource) nextRecord() ([]string, bool) {
+	for {
+		record, err := source.reader.Read()
+		if err == io.EOF {
+			return nil, false
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		isFirst := source.isFirst
+		source.isFirst = false
+		if isFirst && isCsvHeader(record) {
+			continue
+		}
+
+		return record, true
+	}
+}
+
+func (source *CsvCandleSource) Close() {
+	source.file.Close()
+}
+
+func isCsvHeader(record []string) bool {
+	_, err := strconv.ParseInt(record[OPEN_TIME], 10, 64)
+	return err != nil
+}
+
+func CsvRecordToCandle(record []string, symbol string) Candle {
+	return csvRecordToCandleRecord(record).ToCandle(symbol)
+}
+
+func csvRecordToCandleRecord(record []string) candleRecord {
+	openTime, openTimeErr := strconv.ParseInt(record[OPEN_TIME], 10, 64)
+	closeTime, closeTimeErr := strconv.ParseInt(record[CLOSE_TIME], 10, 64)
+	if openTimeErr != nil || closeTimeErr != nil {
+		panic(fmt.Sprintf("Can not convert CSV candle: %v", record))
+	}
+
+	return candleRecord{
+		OpenTime:                 openTime,
+		CloseTime:                closeTime,
+		OpenPrice:                convertStringToFloat64(record[OPEN_PRICE]),
+		HighPrice:                convertStringToFloat64(record[HIGH_PRICE]),
+		LowPrice:                 convertStringToFloat64(record[LOW_PRICE]),
+		ClosePrice:               convertStringToFloat64(record[CLOSE_PRICE]),
+		Volume:                   convertStringToFloat64(record[VOLUME]),
+		QuoteAssetVolume:         convertStringToFloat64(record[QUOTE_ASSET_VOLUME]),
+		NumberOfTrades:           int64(convertStringToInt(record[NUMBER_OF_TRADES])),
+		TakerBuyBaseAssetVolume:  convertStringToFloat64(record[TAKER_BUY_BASE_ASSET_VOLUME]),
+		TakerBuyQuoteAssetVolume: convertStringToFloat64(record[TAKER_BUY_QUOTE_ASSET_VOLUME]),
+	}
+}
+
+// --------------------------------
+
+// Raw kline values as they are stored in the datasets. The binary cache keeps
+// them as fixed size little endian records, so a month of 1m candles is read
+// without parsing text again.
+type candleRecord struct {
+	OpenTime                 int64
+	CloseTime                int64
+	OpenPrice                float64
+	HighPrice                float64
+	LowPrice                 float64
+	ClosePrice               float64
+	Volume                   float64
+	QuoteAssetVolume         float64
+	NumberOfTrades           int64
+	TakerBuyBaseAssetVolume  float64
+	TakerBuyQuoteAssetVolume float64
+}
+
+func (record candleRecord) ToCandle(symbol string) Candle {
+	return Candle{
+		Symbol:                   symbol,
+		OpenTime:                 ParseMilliTimestamp(record.OpenTime),
+		OpenPrice:                record.OpenPrice,
+		HighPrice:                record.HighPrice,
+		LowPrice:                 record.LowPrice,
+		ClosePrice:               record.ClosePrice,
+		Volume:                   record.Volume,
+		CloseTime:                ParseMilliTimestamp(record.CloseTime),
+		QuoteAssetVolume:         record.QuoteAssetVolume,
+		NumberOfTrades:           record.NumberOfTrades,
+		TakerBuyBaseAssetVolume:  record.TakerBuyBaseAssetVolume,
+		TakerBuyQuoteAssetVolume: record.TakerBuyQuoteAssetVolume,
+		Ignore:                   0,
+	}
+}
+
+func (record candleRecord) ToCsvRecord() []string {
+	return []string{
+		strconv.FormatInt(record.OpenTime, 10),
+		strconv.FormatFloat(record.OpenPrice, 'f', 8, 64),
+		strconv.FormatFloat(record.HighPrice, 'f', 8, 64),
+		strconv.FormatFloat(record.LowPrice, 'f', 8, 64),
+		strconv.FormatFloat(record.ClosePrice, 'f', 8, 64),
+		strconv.FormatFloat(record.Volume, 'f', 8, 64),
+		strconv.FormatInt(record.CloseTime, 10),
+		strconv.FormatFloat(record.QuoteAssetVolume, 'f', 8, 64),
+		strconv.FormatInt(record.NumberOfTrades, 10),
+		strconv.FormatFloat(record.TakerBuyBaseAssetVolume, 'f', 8, 64),
+		strconv.FormatFloat(record.TakerBuyQuoteAssetVolume, 'f', 8, 64),
+		"0",
+	}
+}
+
+type BinaryCandleSource struct {
+	symbol string
+	file   *os.File
+	reader *bufio.Reader
+}
+
+func NewBinaryCandleSource(fileName, symbol string) BinaryCandleSource {
+	file, err := os.Open(fileName)
+	if err != nil {
+		panic(err)
+	}
+
+	return BinaryCandleSource{
+		symbol: symbol,
+		file:   file,
+		reader: bufio.NewReader(file),
+	}
+}
+
+func (source *BinaryCandleSource) Next() (Candle, bool) {
+	record := candleRecord{}
+	err := binary.Read(source.reader, binary.LittleEndian, &record)
+	if err == io.EOF {
+		return Candle{}, false
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	return record.ToCandle(source.symbol), true
+}
+
+func (source *BinaryCandleSource) Close() {
+	source.file.Close()
+}
+
+// Streams the CSV file and writes the binary cache next to it. The cache is
+// renamed into place only when the whole CSV file has been read.
+type CachingCsvCandleSource struct {
+	csvSource     CsvCandleSource
+	cacheFileName string
+	tempFile      *os.File
+	writer        *bufio.Writer
+	isDone        bool
+}
+
+func NewCachingCsvCandleSource(fileName, cacheFileName, symbol string) CachingCsvCandleSource {
+	if err := os.MkdirAll(filepath.Dir(cacheFileName), 0755); err != nil {
+		panic(err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(cacheFileName), filepath.Base(cacheFileName)+".*.tmp")
+	if err != nil {
+		panic(err)
+	}
+
+	return CachingCsvCandleSource{
+		csvSource:     NewCsvCandleSource(fileName, symbol),
+		cacheFileName: cacheFileName,
+		tempFile:      tempFile,
+		writer:        bufio.NewWriter(tempFile),
+	}
+}
+
+func (source *CachingCsvCandleSource) Next() (Candle, bool) {
+	record, ok := source.csvSource.nextRecord()
+	if !ok {
+		source.isDone = true
+		return Candle{}, false
+	}
+
+	source.writeRecord(record)
+
+	return CsvRecordToCandle(record, source.csvSource.symbol), true
+}
+
+func (source *CachingCsvCandleSource) writeRecord(record []string) {
+	binaryRecord := csvRecordToCandleRecord(record)
+	if err := binary.Write(source.writer, binary.LittleEndian, &binaryRecord); err != nil {
+		panic(err)
+	}
+}
+
+func (source *CachingCsvCandleSource) Close() {
+	source.csvSource.Close()
+
+	flushErr := source.writer.Flush()
+	closeErr := source.tempFile.Close()
+	if !source.isDone || flushErr != nil || closeErr != nil {
+		os.Remove(source.tempFile.Name())
+		return
+	}
+
+	if err := os.Rename(source.tempFile.Name(), source.cacheFileName); err != nil {
+		os.Remove(source.tempFile.Name())
+	}
+}
+
+// --------------------------------
+
+type DatasetCandleSource struct {
+	dates   []string
+	current CandleSource
+}
+
+func NewDatasetCandleSource(dates []string) DatasetCandleSource {
+	return DatasetCandleSource{dates: dates}
+}
+
+func (source *DatasetCandleSource) Next() (Candle, bool) {
+	for {
+		if source.current == nil {
+			if 0 == len(source.dates) {
+				return Candle{}, false
+			}
+
+			source.current = openDatasetCandleSource(source.dates[0])
+			source.dates = source.dates[1:]
+		}
+
+		if candle, ok := source.current.Next(); ok {
+			return candle, true
+		}
+
+		source.current.Close()
+		source.current = nil
+	}
+}
+
+func (source *DatasetCandleSource) Close() {
+	if source.current != nil {
+		source.current.Close()
+		source.current = nil
+	}
+}
+
+func GetDatasetFileName(date string) string {
+	return fmt.Sprintf("%s/%s-%s-%s.csv", DATASETS_DIRECTORY, CANDLE_SYMBOL, CANDLE_INTERVAL, date)
+}
+
+func GetDatasetCacheFileName(date string) string {
+	return fmt.Sprintf("%s/%s-%s-%s.bin", DATASETS_CACHE_DIRECTORY, CANDLE_SYMBOL, CANDLE_INTERVAL, date)
+}
+
+func openDatasetCandleSource(date string) CandleSource {
+	fileName := GetDatasetFileName(date)
+	cacheFileName := GetDatasetCacheFileName(date)
+
+	if ENABLE_DATASETS_CACHE && FileExists(cacheFileName) && isCacheFresh(fileName, cacheFileName) {
+		source := NewBinaryCandleSource(cacheFileName, CANDLE_SYMBOL)
+		return &source
+	}
+
+	if !FileExists(fileName) {
+		panic(fmt.Sprintf("No dataset for date: %s", fileName))
+	}
+
+	if ENABLE_DATASETS_CACHE {
+		source := NewCachingCsvCandleSource(fileName, cacheFileName, CANDLE_SYMBOL)
+		return &source
+	}
+
+	source := NewCsvCandleSource(fileName, CANDLE_SYMBOL)
+	return &source
+}
+
+func isCacheFresh(fileName, cacheFileName string) bool {
+	csvInfo, err := os.Stat(fileName)
+	if err != nil {
+		// Only the cache is left, use it
+		return true
+	}
+
+	cacheInfo, err := os.Stat(cacheFileName)
+	if err != nil {
+		return false
+	}
+
+	return !cacheInfo.ModTime().Before(csvInfo.ModTime())
+}
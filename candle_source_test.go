@@ -0,0 +1,44 @@
+package main
+
+import "testing"
+
+func TestWindowCandleSourceIsSliceOfSource(t *testing.T) {
+	candles := newTestPriceCandles(1, 2, 3, 4, 5, 6)
+
+	tests := []struct {
+		start int
+		end   int
+	}{
+		{start: 0, end: 6},
+		{start: 2, end: 5},
+		{start: 0, end: 0},
+		{start: 4, end: 10},
+	}
+
+	for _, test := range tests {
+		source := NewSliceCandleSource(candles)
+		window := NewWindowCandleSource(&source, test.start, test.end)
+
+		end := test.end
+		if end > len(candles) {
+			end = len(candles)
+		}
+
+		var prices []float64
+		for _, candle := range CollectCandles(&window) {
+			prices = append(prices, candle.ClosePrice)
+		}
+
+		var expected []float64
+		for _, candle := range candles[test.start:end] {
+			expected = append(expected, candle.ClosePrice)
+		}
+
+		if len(prices) != len(expected) {
+			t.Fatalf("window %d:%d: expected %v, got %v", test.start, test.end, expected, prices)
+		}
+		for i := range prices {
+			assertFloat(t, "close price", expected[i], prices[i])
+		}
+	}
+}
@@ -2,9 +2,18 @@ package main
 
 // Main
 const IS_REAL_ENABLED = false
-const ENABLE_FUTURES = true
 const USE_REAL_MONEY = false
 const REAL_MONEY_DB_NAME = "amazing_real"
+const ENABLE_KLINE_RECORDER = true
+const KLINE_RECORDS_DB_NAME = "db/klines.db"
+
+// A variable and not a constant, so the tests run both futures and spot
+var ENABLE_FUTURES = true
+
+// Replay, runs recorded klines against the simulated exchange (IS_REAL_ENABLED must be false)
+const IS_REPLAY_ENABLED = false
+const REPLAY_RECORDS_DB_NAME = KLINE_RECORDS_DB_NAME
+const REPLAY_SPEED = 0.0 // 1 is real speed, 0 is as fast as possible
 
 // Candle
 const CANDLE_SYMBOL = "BTCUSDT"
@@ -12,8 +21,21 @@ const CANDLE_INTERVAL = "30m"
 const BALANCE_MONEY = 1000.0
 const COMMISSION = 0.06
 const DATASETS_DIRECTORY = "datasets"
+const DATASETS_CACHE_DIRECTORY = "datasets/cache"
+const ENABLE_DATASETS_CACHE = true
 const UNSOLD_BUYS_COUNT = 20
 
+// Higher timeframes built from the CANDLE_INTERVAL candles, see timeframe.go
+var HIGHER_TIMEFRAMES = []string{"4h", "1d"}
+
+const HIGHER_TIMEFRAME_BUFFER_SIZE = 100
+
+// Synthetic datasets
+const GENERATE_SYNTHETIC_DATASETS = false
+const SYNTHETIC_SEED = 2019
+const SYNTHETIC_CANDLES_COUNT = 48 * 365
+const SYNTHETIC_MAX_VOLATILITY_MULTIPLIER = 5.0
+
 // Genetic
 const NO_VALIDATION = true
 const BOTS_COUNT = 25
@@ -24,7 +46,72 @@ const DEFAULT_REVENUE = -1000000
 const ENABLE_AVG_TIME = true
 const SELL_TIME_PUNISHMENT = 1.0
 
+// Trace, writes every indicator value on every candle to the CSV (one bot only, see trace.go)
+const ENABLE_TRACE = false
+const TRACE_FILE_NAME = "trace.csv"
+const TRACE_FROM = "" // "2006-01-02 15:04:05" in UTC, empty is no limit
+const TRACE_TO = ""
+const TRACE_NOTIFICATIONS = true // decisive values go to the BUY/SELL notification
+
+// Trading window, see calendar.go
+const TRADING_BLACKOUTS_FILE_NAME = "datasets/blackouts.csv"
+
 const ENABLE_TIME_CANCEL = false
+const STOP_LOSS_LIMIT_PERCENTAGE = 0.5 // spot stop limit price is below the stop price, so the order is filled on a fast fall
+const STOP_LOSS_MAX_PERCENTAGE = 99    // the ATR stop price is at most this percentage below the buy price
+
+// Signals, see signal_combination.go. The BuySignalExpression gene picks one of the buy expressions,
+// or EVOLVED_BUY_SIGNAL_EXPRESSION to build it from the BuySignalTerm genes.
+// The stop loss is opt-in, e.g. "DesiredPrice OR StopLoss" places OCO orders for live spot buys
+const SPOT_SELL_SIGNAL_EXPRESSION = "DesiredPrice"
+const FUTURES_SELL_SIGNAL_EXPRESSION = "Leverage"
+
+// Live take profit order of the DecayingTakeProfit sell indicator is replaced when the target moved by this much
+const DECAY_TAKE_PROFIT_ORDER_STEP_PERCENTAGE = 0.05
+
+// Partial take profit for the TakeProfitLadder sell indicator, e.g. "TakeProfitLadder OR StopLoss".
+// Each step sells Percentage of the bought coins at TargetPercentage above the buy price, the last step sells the rest
+var TAKE_PROFIT_LADDER = []TakeProfitStep{
+	{Percentage: 50, TargetPercentage: 0.5},
+	{Percentage: 50, TargetPercentage: 1.2},
+}
+
+const EVOLVED_BUY_SIGNAL_EXPRESSION = -1
+
+// Buy indicators of the evolved expression, the empty one skips the term. LessThanPreviousBuy is always added
+var BUY_SIGNAL_TERMS = []string{
+	"",
+	"BigFall",
+	"GradientDescent",
+	"BackTrailing",
+	"WaitForPeriod",
+	"BuysCount",
+	"Rsi",
+	"Macd",
+	"Bollinger",
+	"Stochastic",
+	"VolumeSpike",
+	"TakerImbalance",
+	"VwapDeviation",
+	"ObvTrend",
+	"HigherTrend",
+	"Regime",
+	"CircuitBreaker",
+	"TradingWindow",
+}
+
+var BUY_SIGNAL_EXPRESSIONS = []string{
+	"BigFall AND LessThanPreviousBuy",
+	"(BigFall OR Rsi) AND LessThanPreviousBuy",
+	"BigFall AND VolumeSpike AND LessThanPreviousBuy",
+	"(BigFall OR Bollinger) AND TakerImbalance AND LessThanPreviousBuy",
+	"VOTE(BigFall, Rsi, Bollinger, Stochastic, VwapDeviation) AND LessThanPreviousBuy",
+	"SCORE(2*BigFall, Rsi, Macd, VolumeSpike, TakerImbalance, ObvTrend) AND LessThanPreviousBuy",
+	"BigFall AND HigherTrend AND LessThanPreviousBuy",
+	"BigFall AND Regime AND LessThanPreviousBuy",
+	"BigFall AND CircuitBreaker AND LessThanPreviousBuy",
+	"BigFall AND TradingWindow AND LessThanPreviousBuy",
+}
 
 type Config struct {
 	HighSellPercentage float64
@@ -52,6 +139,88 @@ type Config struct {
 	FuturesAvgSellTimeMinutes           int
 	FuturesLeverageActivationPercentage float64
 
+	RsiPeriod             int
+	RsiOversold           float64
+	MacdFastPeriod        int
+	MacdSlowPeriod        int
+	MacdSignalPeriod      int
+	BollingerPeriod       int
+	BollingerDeviation    float64
+	StochasticFastKPeriod int
+	StochasticSlowKPeriod int
+	StochasticSlowDPeriod int
+	StochasticOversold    float64
+
+	VolumeSpikePeriod       int
+	VolumeSpikeMultiplier   float64
+	TakerImbalancePeriod    int
+	TakerSellPercentage     float64
+	VwapPeriod              int
+	VwapDeviationPercentage float64
+	ObvPeriod               int
+
+	BuySignalExpression     int
+	BuySignalVoteCount      int
+	BuySignalScoreThreshold float64
+
+	BuySignalTerm1     int
+	BuySignalNot1      int
+	BuySignalOperator2 int
+	BuySignalTerm2     int
+	BuySignalNot2      int
+	BuySignalOperator3 int
+	BuySignalTerm3     int
+	BuySignalNot3      int
+	BuySignalOperator4 int
+	BuySignalTerm4     int
+	BuySignalNot4      int
+
+	StopLossMode              int
+	StopLossPercentage        float64
+	StopLossAtrPeriod         int
+	StopLossAtrMultiplier     float64
+	StopLossMaxHoldingMinutes int
+
+	DesiredPriceStrategy      int
+	DesiredPriceAtrPeriod     int
+	DesiredPriceAtrMultiplier float64
+	DesiredPriceSwingCandles  int
+
+	HigherTrendTimeframe    int
+	HigherTrendEmaPeriod    int
+	HigherTrendSlopeCandles int
+
+	DcaEnabled                  int
+	DcaSafetyOrdersCount        int
+	DcaPriceDeviationPercentage float64
+	DcaVolumeScale              float64
+	DcaStepScale                float64
+
+	RegimeAdxPeriod           int
+	RegimeAdxThreshold        float64
+	RegimeMaPeriod            int
+	RegimeSlopeCandles        int
+	RegimeVolatilityPeriod    int
+	RegimeVolatilityThreshold float64
+	RegimeMask                int
+
+	CircuitBreakerLossesCount        int
+	CircuitBreakerLiquidationsCount  int
+	CircuitBreakerDrawdownPercentage float64
+	CircuitBreakerCooldownMinutes    int
+
+	TradingWindowStartHour  int
+	TradingWindowHoursCount int
+	TradingWeekdaysMask     int
+
+	ProfitLockActivationPercentage float64
+	ProfitLockStepPercentage       float64
+
+	DecayTakeProfitMode            int
+	DecayTakeProfitMinutes         int
+	DecayTakeProfitFloorPercentage float64
+	DecayTakeProfitRate            float64
+
 	TotalRevenue     float64
 	TotalBuysCount   int
 	UnsoldBuysCount  int
@@ -17,6 +17,7 @@ type BuyType int
 const (
 	Liquidation BuyType = 1
 	TimeCancel  BuyType = 2
+	StopLoss    BuyType = 3
 )
 
 type Buy struct {
@@ -25,17 +26,52 @@ type Buy struct {
 	Coins        float64
 	ExchangeRate float64
 	DesiredPrice float64
-	CreatedAt    string
+	CreatedAt    time.Time
 	RealOrderId  int64
 	RealQuantity float64
 	HasSellOrder int64
+	StopOrderId  int64
+	MoneyAmount  float64
+	PositionId   int64 // id of the DCA base order, 0 when the buy is not a DCA position layer
+	Regime       Regime
+	SoldCoins    float64 // coins of the partial take profit sells, equal to Coins when the buy is sold
 	BuyType      BuyType
+	ExitPrice    float64 // not stored, the price a marked buy leaves the position for
+	SellCoins    float64 // not stored, a take profit tranche sells only these coins, 0 sells the rest
+}
+
+func (buy Buy) GetUnsoldCoins() float64 {
+	return buy.Coins - buy.SoldCoins
+}
+
+func (buy Buy) IsSold() bool {
+	return buy.Id != 0 && buy.SoldCoins >= buy.Coins
+}
+
+type Sell struct {
+	Id          int64
+	BuyId       int64
+	Revenue     float64
+	MoneyAmount float64
+	CreatedAt   time.Time
+}
+
+type TakeProfitOrder struct {
+	BuyId    int64
+	Step     int
+	OrderId  int64
+	Quantity float64
+	Price    float64
 }
 
 func NewDatabase(config Config) Database {
 	//name := time.Now().Format("db/testdb_2006_01_02__15_04_05.db")
 	name := ":memory:"
 
+	if IS_REPLAY_ENABLED {
+		name = time.Now().Format("db/replay_2006_01_02__15_04_05.db")
+	}
+
 	if IS_REAL_ENABLED {
 		name = time.Now().Format("db/real_2006_01_02__15_04_05.db")
 
@@ -47,6 +83,16 @@ func NewDatabase(config Config) Database {
 
 	createBuysTable(connect)
 	createSellsTable(connect)
+	migrateCreatedAt(connect)
+	addColumnIfNotExists(connect, "buys", "stop_order_id", "INTEGER DEFAULT 0")
+	addColumnIfNotExists(connect, "buys", "money_amount", "FLOAT DEFAULT 0")
+	addColumnIfNotExists(connect, "buys", "position_id", "INTEGER DEFAULT 0")
+	addColumnIfNotExists(connect, "buys", "regime", "INTEGER DEFAULT -1")
+	if addColumnIfNotExists(connect, "buys", "sold_coins", "FLOAT DEFAULT 0") {
+		migrateSoldCoins(connect)
+	}
+	createIndicatorStatesTable(connect)
+	createTakeProfitOrdersTable(connect)
 
 	return Database{
 		connect: connect,
@@ -66,10 +112,15 @@ func createBuysTable(connect *sql.DB) sql.Result {
 			coins FLOAT,
 			exchange_rate FLOAT,
 		    desired_price FLOAT,
-			created_at DATETIME,
+			created_at INTEGER,
 		    real_order_id INTEGER,
 			real_quantity FLOAT,
-		    has_sell_order INTEGER
+		    has_sell_order INTEGER,
+			stop_order_id INTEGER DEFAULT 0,
+			money_amount FLOAT DEFAULT 0,
+			position_id INTEGER DEFAULT 0,
+			regime INTEGER DEFAULT -1,
+			sold_coins FLOAT DEFAULT 0
 		);
 	`
 	result, err := connect.Exec(query)
@@ -89,7 +140,43 @@ func createSellsTable(connect *sql.DB) sql.Result {
 			exchange_rate FLOAT,
 			revenue FLOAT,
 			buy_id INT,
-			created_at DATETIME
+			created_at INTEGER
+		);
+	`
+	result, err := connect.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+func createIndicatorStatesTable(connect *sql.DB) sql.Result {
+	query := `
+		CREATE TABLE IF NOT EXISTS indicator_states (
+			name VARCHAR(255) PRIMARY KEY,
+			version INTEGER,
+			state TEXT,
+			updated_at INTEGER
+		);
+	`
+	result, err := connect.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+func createTakeProfitOrdersTable(connect *sql.DB) sql.Result {
+	query := `
+		CREATE TABLE IF NOT EXISTS take_profit_orders (
+			buy_id INTEGER,
+			step INTEGER,
+			order_id INTEGER,
+			quantity FLOAT,
+			price FLOAT,
+			PRIMARY KEY (buy_id, step)
 		);
 	`
 	result, err := connect.Exec(query)
@@ -100,13 +187,89 @@ func createSellsTable(connect *sql.DB) sql.Result {
 	return result
 }
 
+// Older databases kept created_at as local time text, it is converted to UTC milliseconds
+func migrateCreatedAt(connect *sql.DB) {
+	for _, table := range []string{"buys", "sells"} {
+		query := fmt.Sprintf(`
+			UPDATE %s
+			SET created_at = CAST(STRFTIME('%%s', created_at, 'utc') AS INTEGER) * 1000
+			WHERE TYPEOF(created_at) = 'text'
+		`, table)
+
+		if _, err := connect.Exec(query); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Buys were sold by one sell before the take profit ladder
+func migrateSoldCoins(connect *sql.DB) {
+	query := `
+		UPDATE buys
+		SET sold_coins = coins
+		WHERE id IN (SELECT buy_id FROM sells)
+	`
+
+	if _, err := connect.Exec(query); err != nil {
+		panic(err)
+	}
+}
+
+// Returns true when the column was added
+func addColumnIfNotExists(connect *sql.DB, table, column, definition string) bool {
+	var count int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM PRAGMA_TABLE_INFO('%s') WHERE name = $1`, table)
+	if err := connect.QueryRow(query, column).Scan(&count); err != nil {
+		panic(err)
+	}
+
+	if count > 0 {
+		return false
+	}
+
+	if _, err := connect.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		panic(err)
+	}
+
+	return true
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBuy(row rowScanner) Buy {
+	buy := Buy{}
+	var createdAt int64
+
+	row.Scan(
+		&buy.Id,
+		&buy.Symbol,
+		&buy.Coins,
+		&buy.ExchangeRate,
+		&buy.DesiredPrice,
+		&createdAt,
+		&buy.RealOrderId,
+		&buy.RealQuantity,
+		&buy.HasSellOrder,
+		&buy.StopOrderId,
+		&buy.MoneyAmount,
+		&buy.PositionId,
+		&buy.Regime,
+		&buy.SoldCoins,
+	)
+	buy.CreatedAt = ParseMilliTimestamp(createdAt)
+
+	return buy
+}
+
 // User functions
 
-func (db *Database) AddBuy(symbol string, coinsCount, exchangeRate, desiredPrice float64, createdAt string) sql.Result {
+func (db *Database) AddBuy(symbol string, coinsCount, exchangeRate, desiredPrice float64, createdAt time.Time, moneyAmount float64) sql.Result {
 	query := `
-		INSERT INTO buys (symbol, coins, exchange_rate, desired_price, created_at, real_order_id, real_quantity, has_sell_order) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+		INSERT INTO buys (symbol, coins, exchange_rate, desired_price, created_at, real_order_id, real_quantity, has_sell_order, money_amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 	`
-	result, err := db.connect.Exec(query, symbol, coinsCount, exchangeRate, desiredPrice, createdAt, 0, 0.0, 0)
+	result, err := db.connect.Exec(query, symbol, coinsCount, exchangeRate, desiredPrice, createdAt.UnixMilli(), 0, 0.0, 0, moneyAmount)
 	if err != nil {
 		panic(err)
 	}
@@ -114,13 +277,13 @@ func (db *Database) AddBuy(symbol string, coinsCount, exchangeRate, desiredPrice
 	return result
 }
 
-func (db *Database) AddRealBuy(symbol string, coinsCount, exchangeRate, desiredPrice float64, createdAt string, orderId int64, quantity float64) sql.Result {
+func (db *Database) AddRealBuy(symbol string, coinsCount, exchangeRate, desiredPrice float64, createdAt time.Time, orderId int64, quantity, moneyAmount float64) sql.Result {
 	//createdAt := time.Now().Format("2006-01-02 15:04:05")
 	query := `
-		INSERT INTO buys (symbol, coins, exchange_rate, desired_price, created_at, real_order_id, real_quantity, has_sell_order) VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+		INSERT INTO buys (symbol, coins, exchange_rate, desired_price, created_at, real_order_id, real_quantity, has_sell_order, money_amount) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
 	`
 
-	result, err := db.connect.Exec(query, symbol, coinsCount, exchangeRate, desiredPrice, createdAt, orderId, quantity, 0)
+	result, err := db.connect.Exec(query, symbol, coinsCount, exchangeRate, desiredPrice, createdAt.UnixMilli(), orderId, quantity, 0, moneyAmount)
 	if err != nil {
 		panic(err)
 	}
@@ -141,34 +304,124 @@ func (db *Database) UpdateRealBuyOrderId(buyId int64, orderId int64) {
 	}
 }
 
+func (db *Database) UpdateStopOrderId(buyId int64, stopOrderId int64) {
+	query := `
+		UPDATE buys
+		SET stop_order_id = $1
+		WHERE id = $2
+	`
+
+	_, err := db.connect.Exec(query, stopOrderId, buyId)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (db *Database) UpdateBuyRegime(buyId int64, regime Regime) {
+	query := `
+		UPDATE buys
+		SET regime = $1
+		WHERE id = $2
+	`
+
+	_, err := db.connect.Exec(query, int(regime), buyId)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (db *Database) UpdatePositionId(buyId int64, positionId int64) {
+	query := `
+		UPDATE buys
+		SET position_id = $1
+		WHERE id = $2
+	`
+
+	_, err := db.connect.Exec(query, positionId, buyId)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// All layers of a DCA position are sold together, so they share the take profit price
+func (db *Database) UpdatePositionDesiredPrice(positionId int64, desiredPrice float64) {
+	query := `
+		UPDATE buys
+		SET desired_price = $1
+		WHERE position_id = $2
+	`
+
+	_, err := db.connect.Exec(query, desiredPrice, positionId)
+	if err != nil {
+		panic(err)
+	}
+}
+
 func (db *Database) AddSell(
 	symbol string,
 	coinsCount float64,
 	exchangeRate float64,
 	revenue float64,
 	buyId int64,
-	createdAt string,
+	createdAt time.Time,
 ) sql.Result {
 	//createdAt := time.Now().Format("2006-01-02 15:04:05")
 	query := `
 		INSERT INTO sells (symbol, coins, exchange_rate, revenue, buy_id, created_at) VALUES ($1, $2, $3, $4, $5, $6);
 	`
-	result, err := db.connect.Exec(query, symbol, coinsCount, exchangeRate, revenue, buyId, createdAt)
+	result, err := db.connect.Exec(query, symbol, coinsCount, exchangeRate, revenue, buyId, createdAt.UnixMilli())
 	if err != nil {
 		panic(err)
 	}
 
+	// The float rest of the last tranche closes the buy
+	query = `
+		UPDATE buys
+		SET sold_coins = CASE WHEN sold_coins + $1 >= coins * 0.999999 THEN coins ELSE sold_coins + $1 END
+		WHERE id = $2
+	`
+	if _, err := db.connect.Exec(query, coinsCount, buyId); err != nil {
+		panic(err)
+	}
+
 	return result
 }
 
+func (db *Database) FetchSellsAfterId(sellId int64) []Sell {
+	sells := []Sell{}
+	query := `
+		SELECT s.id, s.buy_id, s.revenue, IFNULL(NULLIF(b.money_amount, 0), $1) * s.coins / b.coins, s.created_at
+		FROM sells AS s
+		JOIN buys AS b
+			ON b.id = s.buy_id
+		WHERE s.id > $2
+		ORDER BY s.id
+	`
+
+	rows, err := db.connect.Query(query, db.config.TotalMoneyAmount, sellId)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sell := Sell{}
+		var sellCreatedAt int64
+		rows.Scan(&sell.Id, &sell.BuyId, &sell.Revenue, &sell.MoneyAmount, &sellCreatedAt)
+		sell.CreatedAt = ParseMilliTimestamp(sellCreatedAt)
+		sells = append(sells, sell)
+	}
+
+	return sells
+}
+
 func (db *Database) FetchUnsoldBuysByUpperPercentage(exchangeRate, upperPercentage float64) []Buy {
 	unsoldBuys := []Buy{}
 	query := `
 		SELECT b.*
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL 
+        WHERE b.sold_coins < b.coins 
+            AND b.position_id = 0
             AND (b.exchange_rate + ((b.exchange_rate * $1) / 100)) <= $2   
 	`
 
@@ -179,8 +432,7 @@ func (db *Database) FetchUnsoldBuysByUpperPercentage(exchangeRate, upperPercenta
 	defer rows.Close()
 
 	for rows.Next() {
-		buy := Buy{}
-		rows.Scan(&buy.Id, &buy.Symbol, &buy.Coins, &buy.ExchangeRate, &buy.DesiredPrice, &buy.CreatedAt, &buy.RealOrderId, &buy.RealQuantity, &buy.HasSellOrder)
+		buy := scanBuy(rows)
 		unsoldBuys = append(unsoldBuys, buy)
 	}
 
@@ -192,9 +444,7 @@ func (db *Database) FetchUnsoldBuysByLowerPercentage(exchangeRate, lowerPercenta
 	query := `
 		SELECT b.*
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL 
+        WHERE b.sold_coins < b.coins 
             AND (b.exchange_rate - ((b.exchange_rate * $1) / 100)) >= $2   
 	`
 
@@ -205,8 +455,7 @@ func (db *Database) FetchUnsoldBuysByLowerPercentage(exchangeRate, lowerPercenta
 	defer rows.Close()
 
 	for rows.Next() {
-		buy := Buy{}
-		rows.Scan(&buy.Id, &buy.Symbol, &buy.Coins, &buy.ExchangeRate, &buy.DesiredPrice, &buy.CreatedAt, &buy.RealOrderId, &buy.RealQuantity, &buy.HasSellOrder)
+		buy := scanBuy(rows)
 		unsoldBuys = append(unsoldBuys, buy)
 	}
 
@@ -218,9 +467,7 @@ func (db *Database) FetchUnsoldBuysByDesiredPrice(exchangeRate float64) []Buy {
 	query := `
 		SELECT b.*
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL 
+        WHERE b.sold_coins < b.coins 
             AND b.desired_price <= $1  
 	`
 
@@ -231,8 +478,55 @@ func (db *Database) FetchUnsoldBuysByDesiredPrice(exchangeRate float64) []Buy {
 	defer rows.Close()
 
 	for rows.Next() {
-		buy := Buy{}
-		rows.Scan(&buy.Id, &buy.Symbol, &buy.Coins, &buy.ExchangeRate, &buy.DesiredPrice, &buy.CreatedAt, &buy.RealOrderId, &buy.RealQuantity, &buy.HasSellOrder)
+		buy := scanBuy(rows)
+		unsoldBuys = append(unsoldBuys, buy)
+	}
+
+	return unsoldBuys
+}
+
+func (db *Database) FetchUnsoldPositionBuysByDesiredPrice(exchangeRate float64) []Buy {
+	unsoldBuys := []Buy{}
+	query := `
+		SELECT b.*
+		FROM buys AS b 
+        WHERE b.sold_coins < b.coins 
+            AND b.position_id != 0
+            AND b.desired_price <= $1  
+	`
+
+	rows, err := db.connect.Query(query, exchangeRate)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		buy := scanBuy(rows)
+		unsoldBuys = append(unsoldBuys, buy)
+	}
+
+	return unsoldBuys
+}
+
+func (db *Database) FetchUnsoldPositionBuys(positionId int64) []Buy {
+	unsoldBuys := []Buy{}
+	query := `
+		SELECT b.*
+		FROM buys AS b 
+        WHERE b.sold_coins < b.coins 
+            AND b.position_id = $1
+        ORDER BY b.id
+	`
+
+	rows, err := db.connect.Query(query, positionId)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		buy := scanBuy(rows)
 		unsoldBuys = append(unsoldBuys, buy)
 	}
 
@@ -244,9 +538,7 @@ func (db *Database) FetchUnsoldBuys() []Buy {
 	query := `
 		SELECT b.*
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL
+        WHERE b.sold_coins < b.coins
 	`
 
 	rows, err := db.connect.Query(query)
@@ -256,8 +548,7 @@ func (db *Database) FetchUnsoldBuys() []Buy {
 	defer rows.Close()
 
 	for rows.Next() {
-		buy := Buy{}
-		rows.Scan(&buy.Id, &buy.Symbol, &buy.Coins, &buy.ExchangeRate, &buy.DesiredPrice, &buy.CreatedAt, &buy.RealOrderId, &buy.RealQuantity, &buy.HasSellOrder)
+		buy := scanBuy(rows)
 		unsoldBuys = append(unsoldBuys, buy)
 	}
 
@@ -269,9 +560,7 @@ func (db *Database) FetchUnsoldBuysById(buyIds []int64) []Buy {
 	query := fmt.Sprintf(`
 		SELECT b.*
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL AND b.id IN(%s)
+        WHERE b.sold_coins < b.coins AND b.id IN(%s)
 	`, JoinInt64(buyIds))
 
 	rows, err := db.connect.Query(query, JoinInt64(buyIds))
@@ -281,34 +570,29 @@ func (db *Database) FetchUnsoldBuysById(buyIds []int64) []Buy {
 	defer rows.Close()
 
 	for rows.Next() {
-		buy := Buy{}
-		rows.Scan(&buy.Id, &buy.Symbol, &buy.Coins, &buy.ExchangeRate, &buy.DesiredPrice, &buy.CreatedAt, &buy.RealOrderId, &buy.RealQuantity, &buy.HasSellOrder)
+		buy := scanBuy(rows)
 		unsoldBuys = append(unsoldBuys, buy)
 	}
 
 	return unsoldBuys
 }
 
-func (db *Database) FetchTimeCancelBuys(createdAt string, minutes int) []Buy {
+func (db *Database) FetchTimeCancelBuys(createdAt time.Time, minutes int) []Buy {
 	unsoldBuys := []Buy{}
 	query := `
 		SELECT b.*
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL 
+        WHERE b.sold_coins < b.coins 
             AND b.created_at < $1
 	`
 
-	candleTime := ConvertDateStringToTime(createdAt)
-	zombieDuration := GetCurrentMinusTime(candleTime, minutes)
+	zombieDuration := GetCurrentMinusTime(createdAt, minutes)
 
-	rows, _ := db.connect.Query(query, zombieDuration.Format("2006-01-02 15:04:05"))
+	rows, _ := db.connect.Query(query, zombieDuration.UnixMilli())
 	defer rows.Close()
 
 	for rows.Next() {
-		buy := Buy{}
-		rows.Scan(&buy.Id, &buy.Symbol, &buy.Coins, &buy.ExchangeRate, &buy.DesiredPrice, &buy.CreatedAt, &buy.RealOrderId, &buy.RealQuantity, &buy.HasSellOrder)
+		buy := scanBuy(rows)
 		unsoldBuys = append(unsoldBuys, buy)
 	}
 
@@ -322,9 +606,11 @@ type revenue struct {
 func (db *Database) GetTotalRevenue() float64 {
 	rev := revenue{}
 	query := `
-		SELECT (SUM(revenue) - COUNT(id) * $1) AS rev 
-		FROM sells 
-		GROUP BY symbol
+		SELECT (SUM(s.revenue) - SUM(IFNULL(NULLIF(b.money_amount, 0), $1) * s.coins / b.coins)) AS rev 
+		FROM sells AS s 
+		JOIN buys AS b 
+			ON b.id = s.buy_id 
+		GROUP BY s.symbol
 	`
 	row := (*db).connect.QueryRow(query, db.config.TotalMoneyAmount)
 	row.Scan(&rev.value)
@@ -332,41 +618,53 @@ func (db *Database) GetTotalRevenue() float64 {
 	return rev.value
 }
 
+func (db *Database) GetBuyById(buyId int64) Buy {
+	query := `
+		SELECT *
+		FROM buys
+		WHERE id = $1
+	`
+
+	return scanBuy(db.connect.QueryRow(query, buyId))
+}
+
+func (db *Database) GetLastBuy() (bool, Buy) {
+	query := `
+		SELECT b.*
+		FROM buys AS b 
+		ORDER BY id DESC
+		LIMIT 1
+	`
+	row := (*db).connect.QueryRow(query)
+	buy := scanBuy(row)
+
+	return buy.Id != 0, buy
+}
+
 func (db *Database) GetLastUnsoldBuy() (bool, Buy) {
 	query := `
 		SELECT b.*
 		FROM buys AS b 
-		LEFT JOIN sells AS s 
-		    ON s.buy_id = b.id
-		WHERE s.id IS NULL 
+		WHERE b.sold_coins < b.coins 
 		ORDER BY id DESC
 		LIMIT 1
 	`
 	row := (*db).connect.QueryRow(query)
-	buy := Buy{}
-	row.Scan(
-		&buy.Id,
-		&buy.Symbol,
-		&buy.Coins,
-		&buy.ExchangeRate,
-		&buy.DesiredPrice,
-		&buy.CreatedAt,
-		&buy.RealOrderId,
-		&buy.RealQuantity,
-		&buy.HasSellOrder,
-	)
+	buy := scanBuy(row)
 
-	return buy.CreatedAt != "", buy
+	return buy.Id != 0, buy
 }
 
 func (db *Database) GetFuturesTotalRevenue() float64 {
 	rev := revenue{}
 	query := `
-		SELECT (SUM(revenue) - COUNT(id) * $1) AS rev 
-		FROM sells
-		WHERE revenue > 0
+		SELECT (SUM(s.revenue) - SUM(IFNULL(NULLIF(b.money_amount, 0), $1) * s.coins / b.coins * $2)) AS rev 
+		FROM sells AS s 
+		JOIN buys AS b 
+			ON b.id = s.buy_id 
+		WHERE s.revenue > 0
 	`
-	row := (*db).connect.QueryRow(query, db.config.TotalMoneyAmount*float64(db.config.Leverage))
+	row := (*db).connect.QueryRow(query, db.config.TotalMoneyAmount, float64(db.config.Leverage))
 	row.Scan(&rev.value)
 
 	return rev.value
@@ -397,6 +695,20 @@ func (db *Database) CountLiquidationBuys() int {
 	return count
 }
 
+func (db *Database) GetLiquidationMoneyAmount() float64 {
+	var moneyAmount float64
+	query := `
+		SELECT IFNULL(SUM(IFNULL(NULLIF(b.money_amount, 0), $1) * s.coins / b.coins), 0)
+		FROM sells AS s
+		JOIN buys AS b
+			ON b.id = s.buy_id
+        WHERE s.revenue = 0
+	`
+	(*db).connect.QueryRow(query, db.config.TotalMoneyAmount).Scan(&moneyAmount)
+
+	return moneyAmount
+}
+
 type buysCount struct {
 	value int
 }
@@ -413,14 +725,44 @@ func (db *Database) GetBuysCount() int {
 	return count.value
 }
 
+func (db *Database) GetBuysMoneyAmount() float64 {
+	var moneyAmount float64
+	query := `
+		SELECT IFNULL(SUM(IFNULL(NULLIF(money_amount, 0), $1)), 0)
+		FROM buys 
+	`
+	(*db).connect.QueryRow(query, db.config.TotalMoneyAmount).Scan(&moneyAmount)
+
+	return moneyAmount
+}
+
+func (db *Database) GetLastOpenPositionId() (bool, int64) {
+	var positionId int64
+	query := `
+		SELECT b.position_id
+		FROM buys AS b 
+		WHERE b.sold_coins < b.coins AND b.position_id != 0
+		ORDER BY b.id DESC
+		LIMIT 1
+	`
+	err := db.connect.QueryRow(query).Scan(&positionId)
+	if err == sql.ErrNoRows {
+		return false, 0
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return true, positionId
+}
+
 func (db *Database) CountUnsoldBuys() int {
 	var count int
 	query := `
 		SELECT COUNT(b.id)
 		FROM buys AS b 
-        LEFT JOIN sells AS s 
-        	ON s.buy_id = b.id 
-        WHERE s.id IS NULL
+        WHERE b.sold_coins < b.coins
 	`
 	(*db).connect.QueryRow(query).Scan(&count)
 
@@ -430,9 +772,10 @@ func (db *Database) CountUnsoldBuys() int {
 func (db *Database) GetAvgSellTime() float64 {
 	var sellTime float64
 	query := `
-		SELECT AVG(JULIANDAY(s.created_at) - JULIANDAY(b.created_at))
+		SELECT AVG(s.created_at - b.created_at) / 86400000.0
 		FROM buys AS b
-        INNER JOIN sells AS s ON b.id = s.buy_id
+        INNER JOIN (SELECT buy_id, MAX(created_at) AS created_at FROM sells GROUP BY buy_id) AS s ON b.id = s.buy_id
+        WHERE b.sold_coins >= b.coins
 	`
 	row := db.connect.QueryRow(query)
 	row.Scan(&sellTime)
@@ -447,9 +790,10 @@ func (db *Database) GetAvgSellTime() float64 {
 func (db *Database) GetMedianSellTime() float64 {
 	var sellTimes []float64
 	query := `
-		SELECT (JULIANDAY(s.created_at) - JULIANDAY(b.created_at))
+		SELECT (s.created_at - b.created_at) / 86400000.0
 		FROM buys AS b
-        INNER JOIN sells AS s ON b.id = s.buy_id
+        INNER JOIN (SELECT buy_id, MAX(created_at) AS created_at FROM sells GROUP BY buy_id) AS s ON b.id = s.buy_id
+        WHERE b.sold_coins >= b.coins
 	`
 	rows, err := db.connect.Query(query)
 	if err != nil {
@@ -466,7 +810,7 @@ func (db *Database) GetMedianSellTime() float64 {
 	return Median(sellTimes)
 }
 
-func (db *Database) CanBuyInGivenPeriod(createdAt string, period int) bool {
+func (db *Database) CanBuyInGivenPeriod(createdAt time.Time, period int) bool {
 	var count int
 	query := `
 		SELECT COUNT(id)
@@ -474,9 +818,73 @@ func (db *Database) CanBuyInGivenPeriod(createdAt string, period int) bool {
         WHERE created_at > $1
 	`
 
-	candleTime := ConvertDateStringToTime(createdAt)
-	canNotBuyDuration := GetCurrentMinusTime(candleTime, period)
-	db.connect.QueryRow(query, canNotBuyDuration).Scan(&count)
+	canNotBuyDuration := GetCurrentMinusTime(createdAt, period)
+	db.connect.QueryRow(query, canNotBuyDuration.UnixMilli()).Scan(&count)
 
 	return count == 0
 }
+
+func (db *Database) AddTakeProfitOrder(buyId int64, step int, orderId int64, quantity, price float64) {
+	query := `
+		INSERT OR REPLACE INTO take_profit_orders (buy_id, step, order_id, quantity, price) VALUES ($1, $2, $3, $4, $5);
+	`
+	_, err := db.connect.Exec(query, buyId, step, orderId, quantity, price)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Orders of the steps that are not sold yet
+func (db *Database) FetchTakeProfitOrders(buyId int64) []TakeProfitOrder {
+	orders := []TakeProfitOrder{}
+	query := `
+		SELECT t.buy_id, t.step, t.order_id, t.quantity, t.price
+		FROM take_profit_orders AS t
+		WHERE t.buy_id = $1
+			AND t.step >= (SELECT COUNT(s.id) FROM sells AS s WHERE s.buy_id = t.buy_id)
+		ORDER BY t.step
+	`
+
+	rows, err := db.connect.Query(query, buyId)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order := TakeProfitOrder{}
+		rows.Scan(&order.BuyId, &order.Step, &order.OrderId, &order.Quantity, &order.Price)
+		orders = append(orders, order)
+	}
+
+	return orders
+}
+
+func (db *Database) SaveIndicatorState(name string, version int, state string, updatedAt time.Time) {
+	query := `
+		INSERT OR REPLACE INTO indicator_states (name, version, state, updated_at) VALUES ($1, $2, $3, $4);
+	`
+	_, err := db.connect.Exec(query, name, version, state, updatedAt.UnixMilli())
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (db *Database) FetchIndicatorState(name string) (bool, int, string) {
+	query := `
+		SELECT version, state FROM indicator_states WHERE name = $1;
+	`
+
+	var version int
+	var state string
+	err := db.connect.QueryRow(query, name).Scan(&version, &state)
+	if err == sql.ErrNoRows {
+		return false, 0, ""
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return true, version, state
+}
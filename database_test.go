@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"testing"
+)
+
+// SQLite reads the local time zone once, so every zone is checked in its own test process
+func TestMigrateCreatedAtConvertsLocalTime(t *testing.T) {
+	if expected := os.Getenv("TEST_MIGRATE_CREATED_AT"); expected != "" {
+		checkMigrateCreatedAt(t, expected)
+		return
+	}
+
+	tests := []struct {
+		timeZone string
+		expected int64
+	}{
+		{timeZone: "UTC", expected: 1704078000000},   // 2024-01-01 03:00 UTC
+		{timeZone: "JST-9", expected: 1704045600000}, // 2023-12-31 18:00 UTC
+		{timeZone: "EST5", expected: 1704096000000},  // 2024-01-01 08:00 UTC
+	}
+
+	for _, test := range tests {
+		t.Run(test.timeZone, func(t *testing.T) {
+			command := exec.Command(os.Args[0], "-test.run=^TestMigrateCreatedAtConvertsLocalTime$")
+			command.Env = append(
+				os.Environ(),
+				"TZ="+test.timeZone,
+				"TEST_MIGRATE_CREATED_AT="+strconv.FormatInt(test.expected, 10),
+			)
+
+			if output, err := command.CombinedOutput(); err != nil {
+				t.Errorf("%s\n%s", err, output)
+			}
+		})
+	}
+}
+
+func checkMigrateCreatedAt(t *testing.T, expected string) {
+	config := newTestConfig()
+	db := newTestDatabase(t, &config)
+
+	for _, query := range []string{
+		`INSERT INTO buys (symbol, coins, exchange_rate, created_at) VALUES ('BTCUSDT', 1, 100, '2024-01-01 03:00:00')`,
+		`INSERT INTO sells (symbol, coins, exchange_rate, revenue, buy_id, created_at) VALUES ('BTCUSDT', 1, 101, 101, 1, '2024-01-01 03:00:00')`,
+	} {
+		if _, err := db.connect.Exec(query); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	migrateCreatedAt(db.connect)
+
+	for _, table := range []string{"buys", "sells"} {
+		var createdAt int64
+		if err := db.connect.QueryRow("SELECT created_at FROM " + table).Scan(&createdAt); err != nil {
+			t.Fatal(err)
+		}
+
+		if strconv.FormatInt(createdAt, 10) != expected {
+			t.Errorf("expected %s created at %s, got %d", table, expected, createdAt)
+		}
+	}
+}
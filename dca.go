@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// DCA mode, enabled by the DcaEnabled gene with DcaSafetyOrdersCount > 0. It replaces buying below the previous buy,
+// so it is off in the default search space. Safety orders are simulated only, real money bots refuse DCA.
+// The buy signal opens a position with the base order (TotalMoneyAmount), then safety orders are bought
+// without a signal when the price falls by the deviation from the base order price:
+//
+//	deviation(n) = DcaPriceDeviationPercentage * (1 + DcaStepScale + ... + DcaStepScale^(n-1))
+//	money(n)     = TotalMoneyAmount * DcaVolumeScale^n
+//
+// All layers share the take profit price HighSellPercentage above the average entry price and are sold together.
+func IsDcaEnabled(config *Config) bool {
+	return config.DcaEnabled == 1 && config.DcaSafetyOrdersCount > 0
+}
+
+func CalcSafetyOrderDeviation(config *Config, safetyOrder int) float64 {
+	deviation := 0.0
+	for i := 0; i < safetyOrder; i++ {
+		deviation += config.DcaPriceDeviationPercentage * math.Pow(config.DcaStepScale, float64(i))
+	}
+
+	return deviation
+}
+
+func CalcSafetyOrderMoneyAmount(config *Config, safetyOrder int) float64 {
+	return config.TotalMoneyAmount * math.Pow(config.DcaVolumeScale, float64(safetyOrder))
+}
+
+func CalcPositionTakeProfit(config *Config, layers []Buy) float64 {
+	coins := 0.0
+	cost := 0.0
+	for _, layer := range layers {
+		coins += layer.Coins
+		cost += layer.Coins * layer.ExchangeRate
+	}
+
+	return CalcUpperPrice(cost/coins, config.HighSellPercentage)
+}
+
+// --------------------------------
+
+func (bot *Bot) openPosition() {
+	if hasPosition, _ := bot.db.GetLastOpenPositionId(); hasPosition {
+		return
+	}
+
+	exchangeRate := bot.buffer.GetLastCandleClosePrice()
+	takeProfit := CalcUpperPrice(exchangeRate, bot.Config.HighSellPercentage)
+
+	if buyId := bot.openBuy(bot.Config.TotalMoneyAmount, takeProfit); buyId != 0 {
+		bot.db.UpdatePositionId(buyId, buyId)
+	}
+}
+
+func (bot *Bot) runSafetyOrders() {
+	if !IsDcaEnabled(bot.Config) {
+		return
+	}
+
+	hasPosition, positionId := bot.db.GetLastOpenPositionId()
+	if !hasPosition {
+		return
+	}
+
+	layers := bot.db.FetchUnsoldPositionBuys(positionId)
+	safetyOrder := len(layers)
+	if safetyOrder > bot.Config.DcaSafetyOrdersCount {
+		return
+	}
+
+	baseOrder := bot.db.GetBuyById(positionId)
+	triggerPrice := CalcBottomPrice(baseOrder.ExchangeRate, CalcSafetyOrderDeviation(bot.Config, safetyOrder))
+	if bot.buffer.GetLastCandleClosePrice() > triggerPrice {
+		return
+	}
+
+	buyId := bot.openBuy(CalcSafetyOrderMoneyAmount(bot.Config, safetyOrder), baseOrder.DesiredPrice)
+	if buyId == 0 {
+		return
+	}
+
+	bot.db.UpdatePositionId(buyId, positionId)
+	takeProfit := CalcPositionTakeProfit(bot.Config, bot.db.FetchUnsoldPositionBuys(positionId))
+	bot.db.UpdatePositionDesiredPrice(positionId, takeProfit)
+
+	Log(fmt.Sprintf(
+		"SAFETY_ORDER\nPositionId: %d\nSafetyOrder: %d\nTakeProfit: %f",
+		positionId,
+		safetyOrder,
+		takeProfit,
+	))
+}
+
+func (bot *Bot) getMoneyAmount(buy Buy) float64 {
+	if buy.MoneyAmount > 0 {
+		return buy.MoneyAmount
+	}
+
+	return bot.Config.TotalMoneyAmount
+}
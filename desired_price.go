@@ -0,0 +1,128 @@
+package main
+
+const (
+	FixedDesiredPriceStrategy     = 0
+	AtrDesiredPriceStrategy       = 1
+	SwingHighDesiredPriceStrategy = 2
+	MedianDesiredPriceStrategy    = 3
+)
+
+// Take profit target of a new buy, stored in buys.desired_price
+type DesiredPriceStrategy interface {
+	CalcDesiredPrice(currentPrice float64) float64
+}
+
+func NewDesiredPriceStrategy(config *Config, buffer *Buffer) DesiredPriceStrategy {
+	switch config.DesiredPriceStrategy {
+	case AtrDesiredPriceStrategy:
+		strategy := NewAtrDesiredPrice(config, buffer)
+		return &strategy
+	case SwingHighDesiredPriceStrategy:
+		strategy := NewSwingHighDesiredPrice(config, buffer)
+		return &strategy
+	case MedianDesiredPriceStrategy:
+		strategy := NewMedianDesiredPrice(config, buffer)
+		return &strategy
+	}
+
+	strategy := NewFixedDesiredPrice(config)
+	return &strategy
+}
+
+// --------------------------------
+
+type FixedDesiredPrice struct {
+	config *Config
+}
+
+func NewFixedDesiredPrice(config *Config) FixedDesiredPrice {
+	return FixedDesiredPrice{config: config}
+}
+
+func (strategy *FixedDesiredPrice) CalcDesiredPrice(currentPrice float64) float64 {
+	return CalcUpperPrice(currentPrice, strategy.config.HighSellPercentage)
+}
+
+// --------------------------------
+
+type AtrDesiredPrice struct {
+	config *Config
+	atr    *StreamingAtr
+}
+
+func NewAtrDesiredPrice(config *Config, buffer *Buffer) AtrDesiredPrice {
+	return AtrDesiredPrice{
+		config: config,
+		atr:    buffer.GetAtr(config.DesiredPriceAtrPeriod),
+	}
+}
+
+func (strategy *AtrDesiredPrice) CalcDesiredPrice(currentPrice float64) float64 {
+	if !strategy.atr.IsReady() {
+		return CalcUpperPrice(currentPrice, strategy.config.HighSellPercentage)
+	}
+
+	return currentPrice + strategy.atr.Last()*strategy.config.DesiredPriceAtrMultiplier
+}
+
+// --------------------------------
+
+type SwingHighDesiredPrice struct {
+	config    *Config
+	swingHigh *StreamingRollingExtreme
+}
+
+func NewSwingHighDesiredPrice(config *Config, buffer *Buffer) SwingHighDesiredPrice {
+	return SwingHighDesiredPrice{
+		config:    config,
+		swingHigh: buffer.GetRollingMax("high", config.DesiredPriceSwingCandles, CandleHighPrice),
+	}
+}
+
+// The highest price of the recent candles, the fixed percentage if the price is above it
+func (strategy *SwingHighDesiredPrice) CalcDesiredPrice(currentPrice float64) float64 {
+	upperPrice := CalcUpperPrice(currentPrice, strategy.config.HighSellPercentage)
+
+	if !strategy.swingHigh.IsReady() {
+		return upperPrice
+	}
+
+	swingHigh := strategy.swingHigh.Last()
+	if swingHigh <= currentPrice {
+		return upperPrice
+	}
+
+	return swingHigh
+}
+
+// --------------------------------
+
+type MedianDesiredPrice struct {
+	config *Config
+	buffer *Buffer
+}
+
+func NewMedianDesiredPrice(config *Config, buffer *Buffer) MedianDesiredPrice {
+	return MedianDesiredPrice{
+		config: config,
+		buffer: buffer,
+	}
+}
+
+// The median close price of the last DesiredPriceCandles, at least the fixed percentage
+func (strategy *MedianDesiredPrice) CalcDesiredPrice(currentPrice float64) float64 {
+	upperPrice := CalcUpperPrice(currentPrice, strategy.config.HighSellPercentage)
+
+	candles := strategy.buffer.GetCandles()
+	count := len(candles)
+	if strategy.config.DesiredPriceCandles > count {
+		return upperPrice
+	}
+
+	medPrice := Median(GetClosePrices(candles[count-strategy.config.DesiredPriceCandles:]))
+	if upperPrice < medPrice {
+		return medPrice
+	}
+
+	return upperPrice
+}
@@ -24,16 +24,16 @@ func Fitness(
 	botConfig Config,
 	botNumber int,
 	botRevenue chan BotRevenue,
-	fitnessDatasets *[]Candle,
-	validationDatasets *[]Candle,
+	fitnessSource CandleSourceFactory,
+	validationSource CandleSourceFactory,
 ) {
-	totalRevenue, totalBuysCount, unsoldBuysCount, LiquidationCount, avgSellTime := doBuysAndSells(fitnessDatasets, botConfig)
+	totalRevenue, totalBuysCount, unsoldBuysCount, LiquidationCount, avgSellTime := doBuysAndSells(fitnessSource(), botConfig)
 
 	// Validate bot
 	Log(fmt.Sprintf("Validate bot: %d\n", botNumber))
 	validationTotalRevenue, validationTotalBuysCount, ValidationUnsoldBuysCount, ValidationLiquidationCount, validationAvgSellTime := 0.0, 0, 0, 0, 0.0
 	if !NO_VALIDATION {
-		validationTotalRevenue, validationTotalBuysCount, ValidationUnsoldBuysCount, ValidationLiquidationCount, validationAvgSellTime = doBuysAndSells(validationDatasets, botConfig)
+		validationTotalRevenue, validationTotalBuysCount, ValidationUnsoldBuysCount, ValidationLiquidationCount, validationAvgSellTime = doBuysAndSells(validationSource(), botConfig)
 	}
 
 	botRevenue <- BotRevenue{
@@ -53,19 +53,24 @@ func Fitness(
 	}
 }
 
-func doBuysAndSells(fitnessDatasets *[]Candle, botConfig Config) (float64, int, int, int, float64) {
+func doBuysAndSells(source CandleSource, botConfig Config) (float64, int, int, int, float64) {
 	bot := NewBot(&botConfig)
+	bot.Run(source)
 
-	for _, candle := range *fitnessDatasets {
-		bot.DoStuff(candle)
-	}
+	datasetRevenue, buyCount, unsold, liquidationsCount, avgSellTime := calcBotResults(&bot)
+	bot.Kill()
 
+	return datasetRevenue, buyCount, unsold, liquidationsCount, avgSellTime
+}
+
+func calcBotResults(bot *Bot) (float64, int, int, int, float64) {
+	botConfig := *bot.Config
 	rev := 0.0
 	liquidationsCount := 0
 	if ENABLE_FUTURES {
 		liquidationsCount = bot.db.CountLiquidationBuys()
 		rev = bot.db.GetFuturesTotalRevenue()
-		rev -= float64(liquidationsCount) * bot.Config.TotalMoneyAmount
+		rev -= bot.db.GetLiquidationMoneyAmount()
 
 		timeCancelRevenue := math.Abs(bot.db.GetTimeCancelTotalRevenue())
 		rev -= timeCancelRevenue
@@ -79,15 +84,13 @@ func doBuysAndSells(fitnessDatasets *[]Candle, botConfig Config) (float64, int,
 
 	buyCount := bot.db.GetBuysCount()
 	//commission := float64(buyCount) * COMMISSION
-	commission := calcCommission(botConfig, buyCount)
+	commission := calcCommission(botConfig, bot.db.GetBuysMoneyAmount())
 	datasetRevenue := rev - commission
 	unsold := bot.db.CountUnsoldBuys()
 	//avgSellTime := bot.db.GetMedianSellTime()
 	avgSellTime := bot.db.GetAvgSellTime()
 
 	fmt.Println(unsold)
-	bot.Kill()
-
 	fmt.Println(fmt.Sprintf(" DatasetRevenue: %f, TotalBuys: %d, UnsoldBuys: %d", datasetRevenue, buyCount, unsold))
 
 	return datasetRevenue, buyCount, unsold, liquidationsCount, avgSellTime
@@ -97,11 +100,11 @@ func hasInvalidBuysCount(botConfig Config, liquidationsCount int) bool {
 	return (BALANCE_MONEY / botConfig.TotalMoneyAmount) < float64(liquidationsCount)
 }
 
-func calcCommission(botConfig Config, buyCount int) float64 {
-	usedMoney := botConfig.TotalMoneyAmount
+func calcCommission(botConfig Config, moneyAmount float64) float64 {
+	usedMoney := moneyAmount
 	if ENABLE_FUTURES {
-		usedMoney = botConfig.TotalMoneyAmount * float64(botConfig.Leverage)
+		usedMoney = moneyAmount * float64(botConfig.Leverage)
 	}
 
-	return (float64(buyCount) * usedMoney * COMMISSION) / 100
+	return (usedMoney * COMMISSION) / 100
 }
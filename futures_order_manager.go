@@ -205,6 +205,57 @@ func (manager *FuturesOrderManager) CreateSellOrder(symbol string, stopPrice, qu
 	return 0
 }
 
+func (manager *FuturesOrderManager) CreateStopLossOrder(symbol string, stopPrice, quantity float64) int64 {
+	if !manager.isEnabled {
+		return 0
+	}
+
+	if info, hasLotSize := manager.exchangeInfo.GetInfoForSymbol(symbol); hasLotSize {
+		stopPriceConverted := valueToPriceSize(stopPrice, info.PriceFilter.tickSize)
+
+		fmt.Println(fmt.Sprintf("CreateStopLossOrder: %f, %f, %f", stopPriceConverted, stopPrice, quantity))
+
+		order, err := manager.futuresClient.
+			NewCreateOrderService().
+			Symbol(symbol).
+			Side(futures.SideTypeSell).
+			Type(futures.OrderTypeStopMarket).
+			ReduceOnly(true).
+			Quantity(floatToBinancePrice(quantity)).
+			StopPrice(floatToBinancePrice(stopPriceConverted)).
+			Do(context.Background())
+
+		if err != nil {
+			fmt.Println(err)
+			panic(err)
+		}
+
+		return order.OrderID
+	}
+
+	return 0
+}
+
+// Take profit ladder, one limit order per step
+func (manager *FuturesOrderManager) CreateLadderSellOrders(symbol string, prices []float64, quantity float64) ([]int64, []float64) {
+	if !manager.isEnabled {
+		return nil, nil
+	}
+
+	info, hasLotSize := manager.exchangeInfo.GetInfoForSymbol(symbol)
+	if !hasLotSize {
+		return nil, nil
+	}
+
+	var orderIds []int64
+	quantities := SplitQuantityToLotSize(quantity, info.LotSize.stepSize)
+	for step, price := range prices {
+		orderIds = append(orderIds, manager.CreateSellOrder(symbol, price, quantities[step]))
+	}
+
+	return orderIds, quantities
+}
+
 func (manager *FuturesOrderManager) CancelOrder(symbol string, orderId int64) int64 {
 	if !manager.isEnabled {
 		return 0
@@ -12,6 +12,7 @@ func RunFuturesRealTime() {
 	tgBot, _ = tgbotapi.NewBotAPI(TG_API_KEY)
 
 	candleConverter = NewSecToMinCandleConverter()
+	InitKlineRecorder()
 	config := GetRealBotConfig()
 	realBot = NewFuturesRealBot(&config, client)
 
@@ -35,8 +36,12 @@ func RunFuturesRealTime() {
 }
 
 func KlineEventHandlerFutures(event *futures.WsKlineEvent) {
+	if klineRecorder != nil {
+		klineRecorder.RecordFutures(event, time.Now())
+	}
+
 	secCandle := WebSocketCandleToKlineCandleFutures(event.Kline)
-	fmt.Println(fmt.Sprintf("FUTURES: %s - Coin: %s, Price: %f", secCandle.CloseTime, secCandle.Symbol, secCandle.ClosePrice))
+	fmt.Println(fmt.Sprintf("FUTURES: %s - Coin: %s, Price: %f", FormatTime(secCandle.CloseTime), secCandle.Symbol, secCandle.ClosePrice))
 
 	if convertedCandle, ok := candleConverter.Convert(secCandle); ok {
 		realBot.DoStuff(convertedCandle)
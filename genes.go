@@ -0,0 +1,500 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"math/bits"
+	"math/rand"
+	"reflect"
+	"strings"
+)
+
+const (
+	FixedDistribution       = "fixed"       // always Value, never mutated
+	UniformDistribution     = "uniform"     // between Min and Max, the default
+	LogUniformDistribution  = "log_uniform" // uniform on the log scale, for ranges over several orders
+	IntStepDistribution     = "int_step"    // Min plus a whole number of Step up to Max
+	CategoricalDistribution = "categorical" // one of Values, re-drawn on mutation, for modes and indexes
+	BitMaskDistribution     = "bit_mask"    // between Min and Max, a mutation flips one bit, for masks
+)
+
+// One evolved field of Config. The type comes from the field, int genes have integer bounds
+type Gene struct {
+	Name         string    `json:"name"`
+	Distribution string    `json:"distribution,omitempty"`
+	Min          float64   `json:"min"`
+	Max          float64   `json:"max"`
+	Step         float64   `json:"step,omitempty"`
+	Value        float64   `json:"value,omitempty"`
+	Values       []float64 `json:"values,omitempty"`
+}
+
+type MinMaxInt struct {
+	min int
+	max int
+}
+
+type MinMaxFloat64 struct {
+	min float64
+	max float64
+}
+
+// The genes in the order of the bots CSV columns, a new gene is a Config field and a row here.
+// It is the default search space, a search space file overrides the genes by name
+func GetGenes() []Gene {
+	return []Gene{
+		{Name: "HighSellPercentage", Min: 0.2, Max: 1},
+
+		{Name: "TrailingTopPercentage", Min: 0.2, Max: 0.5},
+		{Name: "TrailingUpdateTimesBeforeFinish", Min: 1, Max: 3},
+
+		{Name: "WaitAfterLastBuyPeriod", Min: 1, Max: 30},
+
+		{Name: "BigFallCandlesCount", Min: 10, Max: 15},
+		{Name: "BigFallSmoothPeriod", Min: 4, Max: 10},
+		{Name: "BigFallPercentage", Min: 0.2, Max: 2},
+
+		{Name: "DesiredPriceCandles", Min: 24 * 20 * 1, Max: 24 * 20 * 7},
+
+		{Name: "GradientDescentCandles", Min: 6, Max: 60},
+		{Name: "GradientDescentPeriod", Min: 1, Max: 6},
+		{Name: "GradientDescentGradient", Min: 0, Max: 5},
+
+		{Name: "TrailingSellActivationAdditionPercentage", Min: 0.4, Max: 1},
+		{Name: "TrailingSellStopPercentage", Min: 0.4, Max: 2},
+
+		{Name: "TotalMoneyAmount", Distribution: FixedDistribution, Value: 1000},
+		{Name: "Leverage", Distribution: FixedDistribution, Value: 10},
+		{Name: "FuturesAvgSellTimeMinutes", Min: 60 * 1, Max: 60 * 24 * 14}, // 1 hour to 14 days
+		{Name: "FuturesLeverageActivationPercentage", Min: 10, Max: 300},
+
+		{Name: "RsiPeriod", Min: 6, Max: 30},
+		{Name: "RsiOversold", Min: 15, Max: 40},
+		{Name: "MacdFastPeriod", Min: 6, Max: 16},
+		{Name: "MacdSlowPeriod", Min: 20, Max: 40},
+		{Name: "MacdSignalPeriod", Min: 5, Max: 12},
+		{Name: "BollingerPeriod", Min: 10, Max: 40},
+		{Name: "BollingerDeviation", Min: 1.5, Max: 3},
+		{Name: "StochasticFastKPeriod", Min: 5, Max: 21},
+		{Name: "StochasticSlowKPeriod", Min: 2, Max: 5},
+		{Name: "StochasticSlowDPeriod", Min: 2, Max: 5},
+		{Name: "StochasticOversold", Min: 10, Max: 30},
+
+		{Name: "VolumeSpikePeriod", Min: 10, Max: 100},
+		{Name: "VolumeSpikeMultiplier", Min: 1.5, Max: 5},
+		{Name: "TakerImbalancePeriod", Min: 1, Max: 10},
+		{Name: "TakerSellPercentage", Min: 50, Max: 75},
+		{Name: "VwapPeriod", Min: 12, Max: 200},
+		{Name: "VwapDeviationPercentage", Min: 0.5, Max: 5},
+		{Name: "ObvPeriod", Min: 3, Max: 50},
+
+		{Name: "BuySignalExpression", Distribution: CategoricalDistribution, Values: append(getIndexValues(len(BUY_SIGNAL_EXPRESSIONS)), EVOLVED_BUY_SIGNAL_EXPRESSION)},
+		{Name: "BuySignalVoteCount", Min: 1, Max: 4},
+		{Name: "BuySignalScoreThreshold", Min: 0.3, Max: 1},
+
+		{Name: "BuySignalTerm1", Distribution: CategoricalDistribution, Values: getRangeValues(1, len(BUY_SIGNAL_TERMS)-1)},
+		{Name: "BuySignalNot1", Distribution: CategoricalDistribution, Values: []float64{0, 1}},
+		{Name: "BuySignalOperator2", Distribution: CategoricalDistribution, Values: []float64{BuySignalAndOperator, BuySignalOrOperator}},
+		{Name: "BuySignalTerm2", Distribution: CategoricalDistribution, Values: getIndexValues(len(BUY_SIGNAL_TERMS))},
+		{Name: "BuySignalNot2", Distribution: CategoricalDistribution, Values: []float64{0, 1}},
+		{Name: "BuySignalOperator3", Distribution: CategoricalDistribution, Values: []float64{BuySignalAndOperator, BuySignalOrOperator}},
+		{Name: "BuySignalTerm3", Distribution: CategoricalDistribution, Values: getIndexValues(len(BUY_SIGNAL_TERMS))},
+		{Name: "BuySignalNot3", Distribution: CategoricalDistribution, Values: []float64{0, 1}},
+		{Name: "BuySignalOperator4", Distribution: CategoricalDistribution, Values: []float64{BuySignalAndOperator, BuySignalOrOperator}},
+		{Name: "BuySignalTerm4", Distribution: CategoricalDistribution, Values: getIndexValues(len(BUY_SIGNAL_TERMS))},
+		{Name: "BuySignalNot4", Distribution: CategoricalDistribution, Values: []float64{0, 1}},
+
+		{Name: "StopLossMode", Distribution: CategoricalDistribution, Values: getRangeValues(StopLossPercentageMode, StopLossHoldingTimeMode)},
+		{Name: "StopLossPercentage", Min: 2, Max: 20},
+		{Name: "StopLossAtrPeriod", Min: 7, Max: 28},
+		{Name: "StopLossAtrMultiplier", Min: 1, Max: 6},
+		{Name: "StopLossMaxHoldingMinutes", Min: 60 * 24, Max: 60 * 24 * 30},
+
+		{Name: "DesiredPriceStrategy", Distribution: CategoricalDistribution, Values: getRangeValues(FixedDesiredPriceStrategy, MedianDesiredPriceStrategy)},
+		{Name: "DesiredPriceAtrPeriod", Min: 7, Max: 28},
+		{Name: "DesiredPriceAtrMultiplier", Min: 1, Max: 6},
+		{Name: "DesiredPriceSwingCandles", Min: 12, Max: 240},
+
+		{Name: "HigherTrendTimeframe", Distribution: CategoricalDistribution, Values: getIndexValues(len(HIGHER_TIMEFRAMES))},
+		{Name: "HigherTrendEmaPeriod", Min: 5, Max: 50},
+		{Name: "HigherTrendSlopeCandles", Min: 1, Max: 5},
+
+		// Off by default, a search space file turns it on with a categorical of 0 and 1
+		{Name: "DcaEnabled", Distribution: FixedDistribution, Value: 0},
+		{Name: "DcaSafetyOrdersCount", Min: 1, Max: 6},
+		{Name: "DcaPriceDeviationPercentage", Min: 0.5, Max: 5},
+		{Name: "DcaVolumeScale", Min: 1, Max: 2.5},
+		{Name: "DcaStepScale", Min: 1, Max: 2},
+
+		{Name: "RegimeAdxPeriod", Min: 7, Max: 28},
+		{Name: "RegimeAdxThreshold", Min: 15, Max: 40},
+		{Name: "RegimeMaPeriod", Min: 10, Max: 100},
+		{Name: "RegimeSlopeCandles", Min: 3, Max: 24},
+		{Name: "RegimeVolatilityPeriod", Min: 12, Max: 96},
+		{Name: "RegimeVolatilityThreshold", Min: 0.2, Max: 2},
+		{Name: "RegimeMask", Distribution: BitMaskDistribution, Min: 1, Max: 1<<RegimesCount - 1},
+
+		{Name: "CircuitBreakerLossesCount", Min: 2, Max: 8},
+		{Name: "CircuitBreakerLiquidationsCount", Min: 1, Max: 3},
+		{Name: "CircuitBreakerDrawdownPercentage", Min: 2, Max: 30},
+		{Name: "CircuitBreakerCooldownMinutes", Min: 60, Max: 60 * 24 * 7},
+
+		{Name: "TradingWindowStartHour", Distribution: CategoricalDistribution, Values: getRangeValues(0, 23)},
+		{Name: "TradingWindowHoursCount", Min: 1, Max: 24},
+		{Name: "TradingWeekdaysMask", Distribution: BitMaskDistribution, Min: 1, Max: 1<<7 - 1},
+
+		{Name: "ProfitLockActivationPercentage", Min: 0.2, Max: 3.0},
+		{Name: "ProfitLockStepPercentage", Min: 0.1, Max: 2.0},
+
+		{Name: "DecayTakeProfitMode", Distribution: CategoricalDistribution, Values: getRangeValues(DecayLinearMode, DecayExponentialMode)},
+		{Name: "DecayTakeProfitMinutes", Min: 60, Max: 60 * 24 * 7},
+		{Name: "DecayTakeProfitFloorPercentage", Min: -5.0, Max: 0.0},
+		{Name: "DecayTakeProfitRate", Min: 0.5, Max: 8.0},
+	}
+}
+
+// Fitness columns of the bots CSV after the genes
+var BOT_RESULT_COLUMNS = []string{
+	"TotalRevenue",
+	"TotalBuysCount",
+	"UnsoldBuysCount",
+	"LiquidationCount",
+	"AvgSellTime",
+
+	"ValidationTotalRevenue",
+	"ValidationTotalBuysCount",
+	"ValidationUnsoldBuysCount",
+	"ValidationLiquidationCount",
+	"ValidationAvgSellTime",
+
+	"Selection",
+}
+
+func GetBotColumns() []string {
+	var columns []string
+	for _, gene := range GetGenes() {
+		columns = append(columns, gene.Name)
+	}
+
+	return append(columns, BOT_RESULT_COLUMNS...)
+}
+
+func (gene Gene) IsInt() bool {
+	return isIntConfigField(gene.Name)
+}
+
+func (gene Gene) GetMinMaxInt() MinMaxInt {
+	return MinMaxInt{min: int(gene.Min), max: int(gene.Max)}
+}
+
+func (gene Gene) GetMinMaxFloat64() MinMaxFloat64 {
+	return MinMaxFloat64{min: gene.Min, max: gene.Max}
+}
+
+func (gene Gene) Sample(random *rand.Rand) float64 {
+	switch gene.Distribution {
+	case FixedDistribution:
+		return gene.Value
+	case LogUniformDistribution:
+		return gene.round(math.Exp(GetRandFloat64(random, math.Log(gene.Min), math.Log(gene.Max))))
+	case IntStepDistribution:
+		return gene.Min + float64(GetRandInt(random, 0, gene.getStepsCount()))*gene.Step
+	case CategoricalDistribution:
+		return gene.Values[GetRandInt(random, 0, len(gene.Values)-1)]
+	}
+
+	if gene.IsInt() {
+		return float64(GetRandIntConfig(random, gene.GetMinMaxInt()))
+	}
+
+	return GetRandFloat64Config(random, gene.GetMinMaxFloat64())
+}
+
+func (gene Gene) Mutate(random *rand.Rand, current float64) float64 {
+	switch gene.Distribution {
+	case FixedDistribution:
+		return gene.Value
+	case CategoricalDistribution:
+		return gene.Sample(random)
+	case BitMaskDistribution:
+		return gene.flipBit(random, current)
+	case LogUniformDistribution:
+		return gene.mutateLog(random, current)
+	}
+
+	var result float64
+	if gene.IsInt() {
+		result = float64(MutateLittleInt(random, int(current), gene.GetMinMaxInt()))
+	} else {
+		result = MutateLittleFloat64(random, current, gene.GetMinMaxFloat64())
+	}
+
+	if gene.Distribution == IntStepDistribution {
+		steps := math.Min(math.Round((result-gene.Min)/gene.Step), float64(gene.getStepsCount()))
+		return gene.Min + steps*gene.Step
+	}
+
+	return result
+}
+
+// Moves the log of the value by up to half of the log range, so small values change as much as big ones
+func (gene Gene) mutateLog(random *rand.Rand, current float64) float64 {
+	logMin := math.Log(gene.Min)
+	logMax := math.Log(gene.Max)
+	logCurrent := math.Log(math.Min(math.Max(current, gene.Min), gene.Max))
+	shift := (logMax - logMin) / 2
+
+	for attempt := 0; attempt <= MAX_ATTEMPTS; attempt++ {
+		logResult := logCurrent + GetRandFloat64(random, -shift, shift)
+		if logResult < logMin || logResult > logMax {
+			continue
+		}
+
+		if result := gene.round(math.Exp(logResult)); result != current {
+			return result
+		}
+	}
+
+	return current
+}
+
+// The bits are tried in a random order, a flip out of the bounds is skipped
+func (gene Gene) flipBit(random *rand.Rand, current float64) float64 {
+	mask := int(current)
+	for _, bit := range random.Perm(bits.Len(uint(gene.Max))) {
+		result := float64(mask ^ 1<<bit)
+		if result >= gene.Min && result <= gene.Max {
+			return result
+		}
+	}
+
+	return current
+}
+
+func (gene Gene) getStepsCount() int {
+	return int(math.Floor((gene.Max-gene.Min)/gene.Step + 1e-9))
+}
+
+func (gene Gene) round(value float64) float64 {
+	if gene.IsInt() {
+		return math.Min(math.Max(math.Round(value), gene.Min), gene.Max)
+	}
+
+	return value
+}
+
+func getIndexValues(count int) []float64 {
+	return getRangeValues(0, count-1)
+}
+
+func getRangeValues(min int, max int) []float64 {
+	var values []float64
+	for value := min; value <= max; value++ {
+		values = append(values, float64(value))
+	}
+
+	return values
+}
+
+// --------------------------------
+
+// The genes with their distributions, in the order of GetGenes
+type SearchSpace []Gene
+
+func NewSearchSpace(genes []Gene) SearchSpace {
+	var space SearchSpace
+	for _, gene := range genes {
+		space = append(space, validateGene(gene))
+	}
+
+	return space
+}
+
+func GetDefaultSearchSpace() SearchSpace {
+	return NewSearchSpace(GetGenes())
+}
+
+// The file is a JSON list of genes, the genes which are not in it keep the default distribution
+func LoadSearchSpace(fileName string) SearchSpace {
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		panic(err)
+	}
+
+	var fileGenes []Gene
+	if err := json.Unmarshal(content, &fileGenes); err != nil {
+		panic(fmt.Sprintf("Search space %s: %s", fileName, err))
+	}
+
+	genes := GetGenes()
+	for _, fileGene := range fileGenes {
+		found := false
+		for i, gene := range genes {
+			if gene.Name == fileGene.Name {
+				genes[i] = fileGene
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			panic(fmt.Sprintf("Search space %s: unknown gene %s", fileName, fileGene.Name))
+		}
+	}
+
+	return NewSearchSpace(genes)
+}
+
+func (space SearchSpace) Export(fileName string) {
+	content, err := json.MarshalIndent(space, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	if err := ioutil.WriteFile(fileName, content, 0644); err != nil {
+		panic(err)
+	}
+}
+
+// Fills the bounds of fixed and categorical genes, so every gene has Min and Max
+func validateGene(gene Gene) Gene {
+	if gene.Distribution == "" {
+		gene.Distribution = UniformDistribution
+	}
+
+	switch gene.Distribution {
+	case FixedDistribution:
+		gene.Min = gene.Value
+		gene.Max = gene.Value
+	case CategoricalDistribution:
+		if len(gene.Values) == 0 {
+			panic(fmt.Sprintf("Gene %s: categorical without values", gene.Name))
+		}
+
+		gene.Min = gene.Values[0]
+		gene.Max = gene.Values[0]
+		for _, value := range gene.Values {
+			gene.Min = math.Min(gene.Min, value)
+			gene.Max = math.Max(gene.Max, value)
+		}
+	case UniformDistribution:
+	case LogUniformDistribution:
+		if gene.Min <= 0 {
+			panic(fmt.Sprintf("Gene %s: log uniform needs a positive min", gene.Name))
+		}
+	case IntStepDistribution:
+		if gene.Step <= 0 {
+			panic(fmt.Sprintf("Gene %s: int step needs a positive step", gene.Name))
+		}
+	case BitMaskDistribution:
+		if !gene.IsInt() || gene.Min < 1 {
+			panic(fmt.Sprintf("Gene %s: bit mask needs an int gene with a positive min", gene.Name))
+		}
+	default:
+		panic(fmt.Sprintf("Gene %s: unknown distribution %s", gene.Name, gene.Distribution))
+	}
+
+	if gene.Min > gene.Max {
+		panic(fmt.Sprintf("Gene %s: min %f is above max %f", gene.Name, gene.Min, gene.Max))
+	}
+
+	if isPositiveGene(gene.Name) && gene.Min <= 0 {
+		panic(fmt.Sprintf("Gene %s: needs a positive min, got %f", gene.Name, gene.Min))
+	}
+
+	if gene.IsInt() {
+		for _, value := range append([]float64{gene.Min, gene.Max, gene.Step}, gene.Values...) {
+			if value != math.Trunc(value) {
+				panic(fmt.Sprintf("Gene %s: int gene with a float value %f", gene.Name, value))
+			}
+		}
+	}
+
+	return gene
+}
+
+// Periods, candle counts, durations, steps, multipliers and rates are lengths or factors,
+// 0 breaks the indicators
+func isPositiveGene(name string) bool {
+	for _, suffix := range []string{"Period", "Candles", "CandlesCount", "Minutes", "StepPercentage", "Multiplier", "Scale", "Rate"} {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// --------------------------------
+
+func getConfigField(config *Config, name string) reflect.Value {
+	field := reflect.ValueOf(config).Elem().FieldByName(name)
+	if !field.IsValid() {
+		panic(fmt.Sprintf("Unknown config field %s", name))
+	}
+
+	return field
+}
+
+func isIntConfigField(name string) bool {
+	field, ok := reflect.TypeOf(Config{}).FieldByName(name)
+	if !ok {
+		panic(fmt.Sprintf("Unknown config field %s", name))
+	}
+
+	switch field.Type.Kind() {
+	case reflect.Int:
+		return true
+	case reflect.Float64:
+		return false
+	}
+
+	panic(fmt.Sprintf("Config field %s is neither int nor float64", name))
+}
+
+// Data frame values are int64 and float64
+func setConfigDataFrameValue(config *Config, name string, value interface{}) {
+	field := getConfigField(config, name)
+	if isIntConfigField(name) {
+		field.SetInt(int64(convertToInt(value)))
+		return
+	}
+
+	field.SetFloat(convertToFloat64(value))
+}
+
+func setConfigStringValue(config *Config, name string, value string) {
+	field := getConfigField(config, name)
+	if isIntConfigField(name) {
+		field.SetInt(int64(convertStringToInt(value)))
+		return
+	}
+
+	field.SetFloat(convertStringToFloat64(value))
+}
+
+// Int genes are sampled and mutated as whole floats
+func setConfigFloat64Value(config *Config, name string, value float64) {
+	field := getConfigField(config, name)
+	if isIntConfigField(name) {
+		field.SetInt(int64(math.Round(value)))
+		return
+	}
+
+	field.SetFloat(value)
+}
+
+func getConfigFloat64Value(config Config, name string) float64 {
+	field := getConfigField(&config, name)
+	if isIntConfigField(name) {
+		return float64(field.Int())
+	}
+
+	return field.Float()
+}
+
+func GetConfigValue(config Config, name string) interface{} {
+	return getConfigField(&config, name).Interface()
+}
@@ -0,0 +1,338 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/rocketlaunchr/dataframe-go"
+	"github.com/rocketlaunchr/dataframe-go/exports"
+	"math"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func exportTestBots(t *testing.T, bots *dataframe.DataFrame) string {
+	var buffer bytes.Buffer
+	if err := exports.ExportToCSV(context.Background(), &buffer, bots); err != nil {
+		t.Fatal(err)
+	}
+
+	return buffer.String()
+}
+
+// One generation of the optimizer without the fitness
+func runTestGeneration(t *testing.T, seed int64) string {
+	random, _ := NewRand(seed)
+	space := GetDefaultSearchSpace()
+
+	parents := InitBotsDataFrame()
+	for i := 0; i < 3; i++ {
+		parents.Append(nil, GetBotConfigMapInterface(InitBotConfig(random, space)))
+	}
+
+	return exportTestBots(t, parents) + exportTestBots(t, MakeChildren(random, space, parents))
+}
+
+func TestSameSeedGivesSameGeneration(t *testing.T) {
+	first := runTestGeneration(t, 42)
+	if second := runTestGeneration(t, 42); first != second {
+		t.Errorf("expected the same generation for the same seed")
+	}
+
+	if other := runTestGeneration(t, 43); first == other {
+		t.Errorf("expected another generation for another seed")
+	}
+}
+
+func TestNewRandResolvesZeroSeed(t *testing.T) {
+	if _, seed := NewRand(0); seed == 0 {
+		t.Errorf("expected a random seed")
+	}
+
+	if _, seed := NewRand(5); seed != 5 {
+		t.Errorf("expected seed 5, got %d", seed)
+	}
+}
+
+func assertTestSpaceBounds(t *testing.T, space SearchSpace, botConfig Config) {
+	t.Helper()
+
+	for _, gene := range space {
+		value := getConfigFloat64Value(botConfig, gene.Name)
+		if value < gene.Min || value > gene.Max {
+			t.Fatalf("gene %s: %f is out of the bounds", gene.Name, value)
+		}
+	}
+}
+
+func writeTestFile(t *testing.T, name string, content string) string {
+	fileName := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return fileName
+}
+
+func TestGenesAreConfigFields(t *testing.T) {
+	// Panics on an unknown field or invalid bounds
+	space := GetDefaultSearchSpace()
+
+	names := map[string]bool{}
+	for _, gene := range space {
+		if names[gene.Name] {
+			t.Errorf("gene %s is duplicated", gene.Name)
+		}
+		names[gene.Name] = true
+	}
+
+	random, _ := NewRand(1)
+	for i := 0; i < 100; i++ {
+		assertTestSpaceBounds(t, space, InitBotConfig(random, space))
+	}
+}
+
+func TestLoadSearchSpaceOverridesGenes(t *testing.T) {
+	space := LoadSearchSpace(writeTestFile(t, "space.json", `[
+		{"name": "Leverage", "min": 5, "max": 20},
+		{"name": "HighSellPercentage", "distribution": "log_uniform", "min": 0.1, "max": 10},
+		{"name": "WaitAfterLastBuyPeriod", "distribution": "int_step", "min": 5, "max": 32, "step": 5},
+		{"name": "StopLossMode", "distribution": "categorical", "values": [0, 2]},
+		{"name": "TrailingTopPercentage", "distribution": "fixed", "value": 0.3}
+	]`))
+
+	defaultSpace := GetDefaultSearchSpace()
+	if len(space) != len(defaultSpace) {
+		t.Fatalf("expected %d genes, got %d", len(defaultSpace), len(space))
+	}
+	for i, gene := range space {
+		if gene.Name != defaultSpace[i].Name {
+			t.Fatalf("gene %d: expected %s, got %s", i, defaultSpace[i].Name, gene.Name)
+		}
+	}
+	if space[2].Name != "TrailingUpdateTimesBeforeFinish" || !reflect.DeepEqual(space[2], defaultSpace[2]) {
+		t.Errorf("expected the default TrailingUpdateTimesBeforeFinish, got %+v", space[2])
+	}
+
+	random, _ := NewRand(2)
+	stopLossModes := map[int]bool{}
+	for i := 0; i < 200; i++ {
+		botConfig := InitBotConfig(random, space)
+		assertTestSpaceBounds(t, space, botConfig)
+
+		if botConfig.WaitAfterLastBuyPeriod%5 != 0 {
+			t.Fatalf("expected a step of 5, got %d", botConfig.WaitAfterLastBuyPeriod)
+		}
+		assertFloat(t, "fixed gene", 0.3, botConfig.TrailingTopPercentage)
+		stopLossModes[botConfig.StopLossMode] = true
+	}
+
+	if !reflect.DeepEqual(stopLossModes, map[int]bool{0: true, 2: true}) {
+		t.Errorf("expected stop loss modes 0 and 2, got %v", stopLossModes)
+	}
+}
+
+func TestSearchSpaceGeneMutation(t *testing.T) {
+	space := NewSearchSpace([]Gene{
+		{Name: "WaitAfterLastBuyPeriod", Distribution: IntStepDistribution, Min: 5, Max: 30, Step: 5},
+		{Name: "StopLossMode", Distribution: CategoricalDistribution, Values: []float64{1, 2}},
+		{Name: "TrailingTopPercentage", Distribution: FixedDistribution, Value: 0.3},
+	})
+
+	random, _ := NewRand(5)
+	for i := 0; i < 200; i++ {
+		if value := space[0].Mutate(random, 15); math.Mod(value, 5) != 0 || value < 5 || value > 30 {
+			t.Fatalf("expected a step of 5, got %f", value)
+		}
+
+		if value := space[1].Mutate(random, 1); value != 1 && value != 2 {
+			t.Fatalf("expected a category, got %f", value)
+		}
+
+		assertFloat(t, "fixed gene", 0.3, space[2].Mutate(random, 0.5))
+	}
+}
+
+func TestLogUniformGeneMutatesInLogSpace(t *testing.T) {
+	space := NewSearchSpace([]Gene{
+		{Name: "HighSellPercentage", Distribution: LogUniformDistribution, Min: 0.01, Max: 100},
+		{Name: "DesiredPriceCandles", Distribution: LogUniformDistribution, Min: 10, Max: 10000},
+	})
+
+	random, _ := NewRand(4)
+	changedCount := 0
+	for i := 0; i < 200; i++ {
+		value := space[0].Mutate(random, 0.01)
+		if value < 0.01 || value > 100 {
+			t.Fatalf("expected a value in the bounds, got %f", value)
+		}
+		if value > 0.1 {
+			changedCount++
+		}
+
+		if value := space[1].Mutate(random, 10); value < 10 || value > 10000 || value != math.Round(value) {
+			t.Fatalf("expected an int in the bounds, got %f", value)
+		}
+	}
+
+	// A percentage mutation of 0.01 never gets above 0.02
+	if changedCount == 0 {
+		t.Errorf("expected the small value to move by orders")
+	}
+}
+
+func TestBitMaskGeneFlipsOneBit(t *testing.T) {
+	space := NewSearchSpace([]Gene{{Name: "RegimeMask", Distribution: BitMaskDistribution, Min: 1, Max: 1<<RegimesCount - 1}})
+
+	random, _ := NewRand(6)
+	for _, current := range []float64{1, 5, 15} {
+		for i := 0; i < 100; i++ {
+			value := space[0].Mutate(random, current)
+			if value < 1 || value > 15 || bits.OnesCount(uint(int(value)^int(current))) != 1 {
+				t.Fatalf("expected one bit of %.0f flipped, got %.0f", current, value)
+			}
+		}
+	}
+}
+
+func TestTradingWindowStartHourMutatesFromZero(t *testing.T) {
+	var startHour Gene
+	for _, gene := range GetDefaultSearchSpace() {
+		if gene.Name == "TradingWindowStartHour" {
+			startHour = gene
+		}
+	}
+
+	random, _ := NewRand(7)
+	for i := 0; i < 100; i++ {
+		if value := startHour.Mutate(random, 0); value != 0 {
+			return
+		}
+	}
+
+	t.Errorf("expected the start hour 0 to mutate")
+}
+
+func TestSearchSpaceExportLoadsBack(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "search_space.json")
+	GetDefaultSearchSpace().Export(fileName)
+
+	if space := LoadSearchSpace(fileName); !reflect.DeepEqual(space, GetDefaultSearchSpace()) {
+		t.Errorf("expected the default search space, got %+v", space)
+	}
+}
+
+func TestLoadSearchSpacePanicsOnInvalidGene(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown gene", content: `[{"name": "Unknown", "min": 0, "max": 1}]`},
+		{name: "unknown distribution", content: `[{"name": "Leverage", "distribution": "normal", "min": 0, "max": 1}]`},
+		{name: "min above max", content: `[{"name": "HighSellPercentage", "min": 2, "max": 1}]`},
+		{name: "log uniform from zero", content: `[{"name": "HighSellPercentage", "distribution": "log_uniform", "min": 0, "max": 1}]`},
+		{name: "int step without step", content: `[{"name": "Leverage", "distribution": "int_step", "min": 1, "max": 10}]`},
+		{name: "float int bounds", content: `[{"name": "Leverage", "min": 1.5, "max": 10}]`},
+		{name: "zero step percentage", content: `[{"name": "ProfitLockStepPercentage", "min": 0, "max": 2}]`},
+		{name: "zero decay rate", content: `[{"name": "DecayTakeProfitRate", "min": 0, "max": 8}]`},
+		{name: "zero decay minutes", content: `[{"name": "DecayTakeProfitMinutes", "min": 0, "max": 60}]`},
+		{name: "fixed zero period", content: `[{"name": "RsiPeriod", "distribution": "fixed", "value": 0}]`},
+		{name: "bit mask from zero", content: `[{"name": "RegimeMask", "distribution": "bit_mask", "min": 0, "max": 15}]`},
+		{name: "categorical without values", content: `[{"name": "StopLossMode", "distribution": "categorical"}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fileName := writeTestFile(t, "space.json", test.content)
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+			}()
+
+			LoadSearchSpace(fileName)
+		})
+	}
+}
+
+func TestImportFromCsvMapsColumnsByHeader(t *testing.T) {
+	random, _ := NewRand(3)
+	expected := InitBotConfig(random, GetDefaultSearchSpace())
+	expected.TotalRevenue = 12.5
+	expected.TotalBuysCount = 4
+
+	// Reversed columns and an unknown one
+	columns := append([]string{"Unknown"}, GetBotColumns()...)
+	for i, j := 0, len(columns)-1; i < j; i, j = i+1, j-1 {
+		columns[i], columns[j] = columns[j], columns[i]
+	}
+
+	var header, values []string
+	row := GetBotConfigMapInterface(expected)
+	for _, column := range columns {
+		header = append(header, column)
+		values = append(values, fmt.Sprintf("%v", row[column]))
+	}
+
+	content := strings.Join(header, ",") + "\n" + strings.Join(values, ",") + "\n"
+	bots := ImportFromCsv(writeTestFile(t, "bots.csv", content))
+	if len(bots) != 1 {
+		t.Fatalf("expected one bot, got %d", len(bots))
+	}
+
+	for _, column := range GetBotColumns() {
+		expectedValue := fmt.Sprintf("%v", GetConfigValue(expected, column))
+		if actualValue := fmt.Sprintf("%v", GetConfigValue(bots[0], column)); expectedValue != actualValue {
+			t.Errorf("%s: expected %s, got %s", column, expectedValue, actualValue)
+		}
+	}
+}
+
+func TestDataFrameRowKeepsConfig(t *testing.T) {
+	random, _ := NewRand(4)
+	expected := InitBotConfig(random, GetDefaultSearchSpace())
+
+	bots := InitBotsDataFrame()
+	bots.Append(nil, GetBotConfigMapInterface(expected))
+
+	if actual := ConvertDataFrameToBotConfig(bots.Row(0, false)); actual != expected {
+		t.Errorf("expected %+v, got %+v", expected, actual)
+	}
+}
+
+func TestDcaIsOffInDefaultSearchSpace(t *testing.T) {
+	space := GetDefaultSearchSpace()
+	random, _ := NewRand(8)
+
+	for i := 0; i < 200; i++ {
+		if config := InitBotConfig(random, space); IsDcaEnabled(&config) {
+			t.Fatalf("expected DCA to be off")
+		}
+	}
+}
+
+func TestDcaCanBeTurnedOnByMutation(t *testing.T) {
+	space := LoadSearchSpace(writeTestFile(t, "space.json", `[
+		{"name": "DcaEnabled", "distribution": "categorical", "values": [0, 1]}
+	]`))
+
+	var dcaEnabled Gene
+	for _, gene := range space {
+		if gene.Name == "DcaEnabled" {
+			dcaEnabled = gene
+		}
+	}
+
+	random, _ := NewRand(9)
+	for i := 0; i < 100; i++ {
+		if dcaEnabled.Mutate(random, 0) == 1 {
+			return
+		}
+	}
+
+	t.Errorf("expected a mutation to turn DCA on")
+}
@@ -22,16 +22,19 @@ func FileExists(fileName string) bool {
 	return !info.IsDir()
 }
 
+const DATE_TIME_LAYOUT = "2006-01-02 15:04:05"
+
 func FormatTimestamp(timestamp int64) string {
-	date := ParseMilliTimestamp(timestamp)
-	//date = date.Add(time.Hour * 3)
-	return date.Format("2006-01-02 15:04:05")
+	return FormatTime(ParseMilliTimestamp(timestamp))
+}
+
+// Times are kept in UTC, formatting is only for logs, plots and the Telegram
+func FormatTime(date time.Time) string {
+	return date.UTC().Format(DATE_TIME_LAYOUT)
 }
 
 func ParseMilliTimestamp(tm int64) time.Time {
-	sec := tm / 1000
-	msec := tm % 1000
-	return time.Unix(sec, msec*int64(time.Millisecond))
+	return time.UnixMilli(tm).UTC()
 }
 
 func Min(values []float64) float64 {
@@ -82,6 +85,22 @@ func MaxInt(values []int) int {
 	return max
 }
 
+func MinInt(values []int) int {
+	if 0 == len(values) {
+		panic("No values for MIN function")
+	}
+
+	min := values[0]
+
+	for _, value := range values {
+		if value < min {
+			min = value
+		}
+	}
+
+	return min
+}
+
 func BuySliceIntersect(buys1, buys2 []Buy) []Buy {
 	var result []Buy
 
@@ -154,22 +173,29 @@ func LogAndPrintAndSendTg(msg string) {
 	SendTgBotMessage(msg)
 }
 
-func GetRandIntConfig(minMax MinMaxInt) int {
-	return GetRandInt(minMax.min, minMax.max)
+// Zero seed is a random one, the resolved seed is returned to reproduce the run
+func NewRand(seed int64) (*rand.Rand, int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	return rand.New(rand.NewSource(seed)), seed
 }
 
-func GetRandFloat64Config(minMax MinMaxFloat64) float64 {
-	return GetRandFloat64(minMax.min, minMax.max)
+func GetRandIntConfig(random *rand.Rand, minMax MinMaxInt) int {
+	return GetRandInt(random, minMax.min, minMax.max)
 }
 
-func GetRandInt(lower int, upper int) int {
-	rand.Seed(time.Now().UnixNano())
-	return lower + rand.Intn(upper-lower+1)
+func GetRandFloat64Config(random *rand.Rand, minMax MinMaxFloat64) float64 {
+	return GetRandFloat64(random, minMax.min, minMax.max)
 }
 
-func GetRandFloat64(lower float64, upper float64) float64 {
-	rand.Seed(time.Now().UnixNano())
-	return lower + rand.Float64()*(upper-lower)
+func GetRandInt(random *rand.Rand, lower int, upper int) int {
+	return lower + random.Intn(upper-lower+1)
+}
+
+func GetRandFloat64(random *rand.Rand, lower float64, upper float64) float64 {
+	return lower + random.Float64()*(upper-lower)
 }
 
 func convertStringToFloat64(typeValue string) float64 {
@@ -226,9 +252,8 @@ func CountInArray(needle float64, array *[]float64) int {
 }
 
 func ConvertDateStringToTime(dateString string) time.Time {
-	layout := "2006-01-02 15:04:05"
-	parsedTime, _ := time.Parse(layout, dateString)
-	return parsedTime
+	parsedTime, _ := time.Parse(DATE_TIME_LAYOUT, dateString)
+	return parsedTime.UTC()
 }
 
 func GetCurrentMinusTime(candleTime time.Time, durationRaw int) time.Time {
@@ -242,6 +267,26 @@ func GetCurrentMinusTime(candleTime time.Time, durationRaw int) time.Time {
 	return candleTime
 }
 
+func GetCandleIntervalDuration(interval string) time.Duration {
+	unit := interval[len(interval)-1:]
+	count := convertStringToInt(interval[:len(interval)-1])
+
+	switch unit {
+	case "s":
+		return time.Duration(count) * time.Second
+	case "m":
+		return time.Duration(count) * time.Minute
+	case "h":
+		return time.Duration(count) * time.Hour
+	case "d":
+		return time.Duration(count) * 24 * time.Hour
+	case "w":
+		return time.Duration(count) * 7 * 24 * time.Hour
+	}
+
+	panic(fmt.Sprintf("Unknown candle interval: %s", interval))
+}
+
 func CalcUpperPrice(price, percentage float64) float64 {
 	return price + ((price * percentage) / 100)
 }
@@ -270,6 +315,36 @@ func GetClosePrices(candles []Candle) []float64 {
 	return values
 }
 
+func GetHighPrices(candles []Candle) []float64 {
+	var values []float64
+
+	for _, candle := range candles {
+		values = append(values, candle.HighPrice)
+	}
+
+	return values
+}
+
+func GetLowPrices(candles []Candle) []float64 {
+	var values []float64
+
+	for _, candle := range candles {
+		values = append(values, candle.LowPrice)
+	}
+
+	return values
+}
+
+func GetVolumes(candles []Candle) []float64 {
+	var values []float64
+
+	for _, candle := range candles {
+		values = append(values, candle.Volume)
+	}
+
+	return values
+}
+
 func GetAvg(values []float64) float64 {
 	total := 0.0
 	for _, value := range values {
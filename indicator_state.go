@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Indicators which keep state between candles, the live bot saves it after every candle
+// and restores it on start, so a restart does not reset trailing stops of open positions.
+// Bump the version when the state format changes, an old state is dropped then.
+type StatefulIndicator interface {
+	GetStateName() string
+	GetStateVersion() int
+	SaveState() string
+	RestoreState(state string)
+}
+
+func (bot *Bot) getStatefulIndicators() []StatefulIndicator {
+	var indicators []StatefulIndicator
+
+	for _, indicator := range bot.BuyIndicators {
+		if stateful, ok := indicator.(StatefulIndicator); ok {
+			indicators = append(indicators, stateful)
+		}
+	}
+
+	for _, indicator := range bot.SellIndicators {
+		if stateful, ok := indicator.(StatefulIndicator); ok {
+			indicators = append(indicators, stateful)
+		}
+	}
+
+	return indicators
+}
+
+func (bot *Bot) SaveIndicatorStates() {
+	candle := bot.buffer.GetLastCandle()
+
+	for _, indicator := range bot.getStatefulIndicators() {
+		bot.db.SaveIndicatorState(
+			indicator.GetStateName(),
+			indicator.GetStateVersion(),
+			indicator.SaveState(),
+			candle.CloseTime,
+		)
+	}
+}
+
+func (bot *Bot) RestoreIndicatorStates() {
+	for _, indicator := range bot.getStatefulIndicators() {
+		hasState, version, state := bot.db.FetchIndicatorState(indicator.GetStateName())
+		if !hasState {
+			continue
+		}
+
+		if version != indicator.GetStateVersion() {
+			Log(fmt.Sprintf(
+				"%s__STATE_DROPPED\nVersion: %d, expected: %d",
+				indicator.GetStateName(),
+				version,
+				indicator.GetStateVersion(),
+			))
+			continue
+		}
+
+		indicator.RestoreState(state)
+		Log(fmt.Sprintf("%s__STATE_RESTORED", indicator.GetStateName()))
+	}
+}
+
+func marshalIndicatorState(state interface{}) string {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(encoded)
+}
+
+func unmarshalIndicatorState(encoded string, state interface{}) {
+	if err := json.Unmarshal([]byte(encoded), state); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// BackTrailing is in the middle of a trailing buy and Trailing holds activated sells
+func newTestTrailingBot(t *testing.T) *Bot {
+	config := newTestConfig()
+	config.TrailingTopPercentage = 0.5
+	config.TrailingUpdateTimesBeforeFinish = 1
+	config.TrailingSellActivationAdditionPercentage = 0.5
+	config.TrailingSellStopPercentage = 0.5
+
+	bot := newTestBot(t, config, "BackTrailing", "Trailing")
+	runTestCandles(bot, newTestPriceCandles(100, 99, 98, 97, 98, 99, 100, 101, 102, 103, 104, 103.5))
+
+	return bot
+}
+
+func getTestIndicatorStates(bot *Bot) map[string]string {
+	states := map[string]string{}
+	for _, indicator := range bot.getStatefulIndicators() {
+		states[indicator.GetStateName()] = indicator.SaveState()
+	}
+
+	return states
+}
+
+func TestIndicatorStatesSurviveRestart(t *testing.T) {
+	bot := newTestTrailingBot(t)
+	bot.SaveIndicatorStates()
+
+	restarted := restartTestBot(bot, "BackTrailing", "Trailing")
+	if expected, actual := getTestIndicatorStates(bot), getTestIndicatorStates(restarted); !reflect.DeepEqual(expected, actual) {
+		t.Errorf("expected states %v, got %v", expected, actual)
+	}
+
+	if _, buyItem := restarted.trailingSellIndicator.GetBuyItemByBuyId(1); !buyItem.isActivated {
+		t.Errorf("expected the trailing sell of buy 1 to be activated")
+	}
+}
+
+func TestIndicatorStateOfOtherVersionIsDropped(t *testing.T) {
+	bot := newTestTrailingBot(t)
+	bot.SaveIndicatorStates()
+
+	trailingState := bot.trailingSellIndicator.SaveState()
+	bot.db.SaveIndicatorState("Trailing", bot.trailingSellIndicator.GetStateVersion()+1, trailingState, testStartTime)
+
+	restarted := restartTestBot(bot, "BackTrailing", "Trailing")
+	if state := restarted.trailingSellIndicator.SaveState(); state != "{}" {
+		t.Errorf("expected the Trailing state to be dropped, got %s", state)
+	}
+
+	states := getTestIndicatorStates(bot)
+	if state := getTestIndicatorStates(restarted)["BackTrailing"]; state != states["BackTrailing"] {
+		t.Errorf("expected the BackTrailing state %s, got %s", states["BackTrailing"], state)
+	}
+}
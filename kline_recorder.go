@@ -0,0 +1,369 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/adshao/go-binance/v2"
+	"github.com/adshao/go-binance/v2/futures"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const SPOT_MARKET = "spot"
+const FUTURES_MARKET = "futures"
+
+var klineRecorder *KlineRecorder
+
+type RecordedKline struct {
+	Id                   int64
+	Market               string
+	Event                string
+	EventTime            int64
+	ReceivedAt           int64
+	Symbol               string
+	Interval             string
+	StartTime            int64
+	EndTime              int64
+	FirstTradeId         int64
+	LastTradeId          int64
+	Open                 string
+	Close                string
+	High                 string
+	Low                  string
+	Volume               string
+	TradeNum             int64
+	IsFinal              bool
+	QuoteVolume          string
+	ActiveBuyVolume      string
+	ActiveBuyQuoteVolume string
+}
+
+type KlineRecorder struct {
+	connect *sql.DB
+}
+
+func NewKlineRecorder(fileName string) KlineRecorder {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		panic(err)
+	}
+
+	connect, err := sql.Open("sqlite3", fileName)
+	if err != nil {
+		panic(err)
+	}
+
+	createKlinesTable(connect)
+
+	return KlineRecorder{connect: connect}
+}
+
+func InitKlineRecorder() {
+	if !ENABLE_KLINE_RECORDER {
+		return
+	}
+
+	recorder := NewKlineRecorder(KLINE_RECORDS_DB_NAME)
+	klineRecorder = &recorder
+}
+
+func createKlinesTable(connect *sql.DB) sql.Result {
+	query := `
+		CREATE TABLE IF NOT EXISTS klines (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			market VARCHAR(16),
+			event VARCHAR(32),
+			event_time INTEGER,
+			received_at INTEGER,
+			symbol VARCHAR(255),
+			interval VARCHAR(8),
+			start_time INTEGER,
+			end_time INTEGER,
+			first_trade_id INTEGER,
+			last_trade_id INTEGER,
+			open TEXT,
+			close TEXT,
+			high TEXT,
+			low TEXT,
+			volume TEXT,
+			trade_num INTEGER,
+			is_final INTEGER,
+			quote_volume TEXT,
+			active_buy_volume TEXT,
+			active_buy_quote_volume TEXT
+		);
+	`
+	result, err := connect.Exec(query)
+	if err != nil {
+		panic(err)
+	}
+
+	return result
+}
+
+func (recorder *KlineRecorder) Close() {
+	recorder.connect.Close()
+}
+
+func (recorder *KlineRecorder) RecordSpot(event *binance.WsKlineEvent, receivedAt time.Time) {
+	kline := event.Kline
+
+	recorder.record(RecordedKline{
+		Market:               SPOT_MARKET,
+		Event:                event.Event,
+		EventTime:            event.Time,
+		ReceivedAt:           receivedAt.UnixMilli(),
+		Symbol:               kline.Symbol,
+		Interval:             kline.Interval,
+		StartTime:            kline.StartTime,
+		EndTime:              kline.EndTime,
+		FirstTradeId:         kline.FirstTradeID,
+		LastTradeId:          kline.LastTradeID,
+		Open:                 kline.Open,
+		Close:                kline.Close,
+		High:                 kline.High,
+		Low:                  kline.Low,
+		Volume:               kline.Volume,
+		TradeNum:             kline.TradeNum,
+		IsFinal:              kline.IsFinal,
+		QuoteVolume:          kline.QuoteVolume,
+		ActiveBuyVolume:      kline.ActiveBuyVolume,
+		ActiveBuyQuoteVolume: kline.ActiveBuyQuoteVolume,
+	})
+}
+
+func (recorder *KlineRecorder) RecordFutures(event *futures.WsKlineEvent, receivedAt time.Time) {
+	kline := event.Kline
+
+	recorder.record(RecordedKline{
+		Market:               FUTURES_MARKET,
+		Event:                event.Event,
+		EventTime:            event.Time,
+		ReceivedAt:           receivedAt.UnixMilli(),
+		Symbol:               kline.Symbol,
+		Interval:             kline.Interval,
+		StartTime:            kline.StartTime,
+		EndTime:              kline.EndTime,
+		FirstTradeId:         kline.FirstTradeID,
+		LastTradeId:          kline.LastTradeID,
+		Open:                 kline.Open,
+		Close:                kline.Close,
+		High:                 kline.High,
+		Low:                  kline.Low,
+		Volume:               kline.Volume,
+		TradeNum:             kline.TradeNum,
+		IsFinal:              kline.IsFinal,
+		QuoteVolume:          kline.QuoteVolume,
+		ActiveBuyVolume:      kline.ActiveBuyVolume,
+		ActiveBuyQuoteVolume: kline.ActiveBuyQuoteVolume,
+	})
+}
+
+func (recorder *KlineRecorder) record(kline RecordedKline) {
+	query := `
+		INSERT INTO klines (
+			market, event, event_time, received_at, symbol, interval, start_time, end_time, first_trade_id, last_trade_id,
+			open, close, high, low, volume, trade_num, is_final, quote_volume, active_buy_volume, active_buy_quote_volume
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20);
+	`
+
+	_, err := recorder.connect.Exec(
+		query,
+		kline.Market,
+		kline.Event,
+		kline.EventTime,
+		kline.ReceivedAt,
+		kline.Symbol,
+		kline.Interval,
+		kline.StartTime,
+		kline.EndTime,
+		kline.FirstTradeId,
+		kline.LastTradeId,
+		kline.Open,
+		kline.Close,
+		kline.High,
+		kline.Low,
+		kline.Volume,
+		kline.TradeNum,
+		kline.IsFinal,
+		kline.QuoteVolume,
+		kline.ActiveBuyVolume,
+		kline.ActiveBuyQuoteVolume,
+	)
+
+	// Losing a record must not stop the trading
+	if err != nil {
+		fmt.Println(err)
+	}
+}
+
+// --------------------------------
+
+type RecordedKlines struct {
+	connect *sql.DB
+	rows    *sql.Rows
+}
+
+func OpenRecordedKlines(fileName, market, symbol, interval string) RecordedKlines {
+	if !FileExists(fileName) {
+		panic(fmt.Sprintf("No kline records: %s", fileName))
+	}
+
+	connect, err := sql.Open("sqlite3", fileName)
+	if err != nil {
+		panic(err)
+	}
+
+	query := `
+		SELECT id, market, event, event_time, received_at, symbol, interval, start_time, end_time, first_trade_id, last_trade_id,
+			open, close, high, low, volume, trade_num, is_final, quote_volume, active_buy_volume, active_buy_quote_volume
+		FROM klines
+		WHERE market = $1 AND symbol = $2 AND interval = $3
+		ORDER BY id
+	`
+	rows, err := connect.Query(query, market, symbol, interval)
+	if err != nil {
+		panic(err)
+	}
+
+	return RecordedKlines{
+		connect: connect,
+		rows:    rows,
+	}
+}
+
+func (klines *RecordedKlines) Next() (RecordedKline, bool) {
+	if !klines.rows.Next() {
+		if err := klines.rows.Err(); err != nil {
+			panic(err)
+		}
+
+		return RecordedKline{}, false
+	}
+
+	kline := RecordedKline{}
+	err := klines.rows.Scan(
+		&kline.Id,
+		&kline.Market,
+		&kline.Event,
+		&kline.EventTime,
+		&kline.ReceivedAt,
+		&kline.Symbol,
+		&kline.Interval,
+		&kline.StartTime,
+		&kline.EndTime,
+		&kline.FirstTradeId,
+		&kline.LastTradeId,
+		&kline.Open,
+		&kline.Close,
+		&kline.High,
+		&kline.Low,
+		&kline.Volume,
+		&kline.TradeNum,
+		&kline.IsFinal,
+		&kline.QuoteVolume,
+		&kline.ActiveBuyVolume,
+		&kline.ActiveBuyQuoteVolume,
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	return kline, true
+}
+
+func (klines *RecordedKlines) Close() {
+	klines.rows.Close()
+	klines.connect.Close()
+}
+
+func (kline RecordedKline) ToSpotEvent() *binance.WsKlineEvent {
+	return &binance.WsKlineEvent{
+		Event:  kline.Event,
+		Time:   kline.EventTime,
+		Symbol: kline.Symbol,
+		Kline: binance.WsKline{
+			StartTime:            kline.StartTime,
+			EndTime:              kline.EndTime,
+			Symbol:               kline.Symbol,
+			Interval:             kline.Interval,
+			FirstTradeID:         kline.FirstTradeId,
+			LastTradeID:          kline.LastTradeId,
+			Open:                 kline.Open,
+			Close:                kline.Close,
+			High:                 kline.High,
+			Low:                  kline.Low,
+			Volume:               kline.Volume,
+			TradeNum:             kline.TradeNum,
+			IsFinal:              kline.IsFinal,
+			QuoteVolume:          kline.QuoteVolume,
+			ActiveBuyVolume:      kline.ActiveBuyVolume,
+			ActiveBuyQuoteVolume: kline.ActiveBuyQuoteVolume,
+		},
+	}
+}
+
+func (kline RecordedKline) ToFuturesEvent() *futures.WsKlineEvent {
+	return &futures.WsKlineEvent{
+		Event:  kline.Event,
+		Time:   kline.EventTime,
+		Symbol: kline.Symbol,
+		Kline: futures.WsKline{
+			StartTime:            kline.StartTime,
+			EndTime:              kline.EndTime,
+			Symbol:               kline.Symbol,
+			Interval:             kline.Interval,
+			FirstTradeID:         kline.FirstTradeId,
+			LastTradeID:          kline.LastTradeId,
+			Open:                 kline.Open,
+			Close:                kline.Close,
+			High:                 kline.High,
+			Low:                  kline.Low,
+			Volume:               kline.Volume,
+			TradeNum:             kline.TradeNum,
+			IsFinal:              kline.IsFinal,
+			QuoteVolume:          kline.QuoteVolume,
+			ActiveBuyVolume:      kline.ActiveBuyVolume,
+			ActiveBuyQuoteVolume: kline.ActiveBuyQuoteVolume,
+		},
+	}
+}
+
+// --------------------------------
+
+// Final klines of a recording, the same candles the bot got in live mode.
+type RecordedCandleSource struct {
+	klines        RecordedKlines
+	lastStartTime int64
+}
+
+func NewRecordedCandleSource(fileName, market, symbol, interval string) RecordedCandleSource {
+	return RecordedCandleSource{
+		klines:        OpenRecordedKlines(fileName, market, symbol, interval),
+		lastStartTime: -1,
+	}
+}
+
+func (source *RecordedCandleSource) Next() (Candle, bool) {
+	for {
+		kline, ok := source.klines.Next()
+		if !ok {
+			return Candle{}, false
+		}
+
+		if !kline.IsFinal || kline.StartTime == source.lastStartTime {
+			continue
+		}
+		source.lastStartTime = kline.StartTime
+
+		if kline.Market == FUTURES_MARKET {
+			return WebSocketCandleToKlineCandleFutures(kline.ToFuturesEvent().Kline), true
+		}
+
+		return WebSocketCandleToKlineCandle(kline.ToSpotEvent().Kline), true
+	}
+}
+
+func (source *RecordedCandleSource) Close() {
+	source.klines.Close()
+}
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 )
 
 func main() {
+	seed := flag.Int64("seed", 0, "Seed of the genetic optimizer, 0 is a random seed")
+	space := flag.String("space", "", "Search space JSON file of the genetic optimizer, empty is the default space")
+	flag.Parse()
+
 	// Logger
 	logFileName := resolveLogFileName()
 	_, e := os.OpenFile(logFileName, os.O_RDONLY, 0666)
@@ -23,6 +28,16 @@ func main() {
 	defer f.Close()
 	log.SetOutput(f)
 
+	if GENERATE_SYNTHETIC_DATASETS {
+		RunSyntheticDatasetsGenerator()
+		return
+	}
+
+	if IS_REPLAY_ENABLED {
+		RunReplay()
+		return
+	}
+
 	if IS_REAL_ENABLED {
 		if ENABLE_FUTURES {
 			RunFuturesRealTime()
@@ -32,10 +47,14 @@ func main() {
 		return
 	}
 
-	RunTest()
+	RunTest(*seed, *space)
 }
 
 func resolveLogFileName() string {
+	if IS_REPLAY_ENABLED {
+		return "replay_bot_log.txt"
+	}
+
 	if IS_REAL_ENABLED {
 		return "real_bot_log.txt"
 	}
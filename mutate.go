@@ -1,8 +1,10 @@
 package main
 
+import "math/rand"
+
 const MAX_ATTEMPTS = 10
 
-func MutateLittleFloat64(current float64, minMax MinMaxFloat64) float64 {
+func MutateLittleFloat64(random *rand.Rand, current float64, minMax MinMaxFloat64) float64 {
 	//if !shouldMutate() {
 	//	return current
 	//}
@@ -11,7 +13,7 @@ func MutateLittleFloat64(current float64, minMax MinMaxFloat64) float64 {
 	result := current
 
 	for {
-		value := MutatePercentFloat(current)
+		value := MutatePercentFloat(random, current)
 		result = current - value
 
 		if minMax.min > result || result > minMax.max {
@@ -27,7 +29,7 @@ func MutateLittleFloat64(current float64, minMax MinMaxFloat64) float64 {
 	return result
 }
 
-func MutateLittleInt(current int, minMax MinMaxInt) int {
+func MutateLittleInt(random *rand.Rand, current int, minMax MinMaxInt) int {
 	//if !shouldMutate() {
 	//	return current
 	//}
@@ -35,7 +37,7 @@ func MutateLittleInt(current int, minMax MinMaxInt) int {
 	result := current
 	attemptsCount := 0
 	for {
-		value := MutatePercentInt(current)
+		value := MutatePercentInt(random, current)
 		result = current - value
 
 		if minMax.min > result || result > minMax.max {
@@ -51,30 +53,30 @@ func MutateLittleInt(current int, minMax MinMaxInt) int {
 	return result
 }
 
-func MutatePercentFloat(current float64) float64 {
+func MutatePercentFloat(random *rand.Rand, current float64) float64 {
 	dir := 1.0
-	mutatePercent := GetRandFloat64(0, 100)
+	mutatePercent := GetRandFloat64(random, 0, 100)
 	mutateValue := (current * mutatePercent) / 100
 
-	if GetRandInt(0, 1) == 1 {
+	if GetRandInt(random, 0, 1) == 1 {
 		dir = -1.0
 	}
 
 	return dir * mutateValue
 }
 
-func MutatePercentInt(current int) int {
+func MutatePercentInt(random *rand.Rand, current int) int {
 	dir := 1
-	mutatePercent := int(GetRandInt(0, 100))
+	mutatePercent := int(GetRandInt(random, 0, 100))
 	mutateValue := int((current * mutatePercent) / 100)
 
-	if GetRandInt(0, 1) == 1 {
+	if GetRandInt(random, 0, 1) == 1 {
 		dir = -1
 	}
 
 	return dir * mutateValue
 }
 
-func shouldMutate() bool {
-	return GetRandInt(0, 5) == 1
+func shouldMutate(random *rand.Rand) bool {
+	return GetRandInt(random, 0, 5) == 1
 }
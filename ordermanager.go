@@ -232,6 +232,53 @@ func (manager *OrderManager) CreateSellOrder(symbol string, stopPrice, quantity
 	return 0
 }
 
+// Take profit limit order and stop loss limit order, the exchange cancels one when the other is filled
+func (manager *OrderManager) CreateOcoSellOrder(symbol string, price, stopPrice, quantity float64) (int64, int64) {
+	if !manager.isEnabled {
+		return 0, 0
+	}
+
+	if info, hasLotSize := manager.exchangeInfo.GetInfoForSymbol(symbol); hasLotSize {
+		priceConverted := valueToPriceSize(price, info.PriceFilter.tickSize)
+		stopPriceConverted := valueToPriceSize(stopPrice, info.PriceFilter.tickSize)
+		stopLimitPriceConverted := valueToPriceSize(
+			CalcBottomPrice(stopPrice, STOP_LOSS_LIMIT_PERCENTAGE),
+			info.PriceFilter.tickSize,
+		)
+
+		fmt.Println(fmt.Sprintf("CreateOcoSellOrder: %f, %f, %f", priceConverted, stopPriceConverted, quantity))
+
+		order, err := manager.binanceClient.
+			NewCreateOCOService().
+			Symbol(symbol).
+			Side(binance.SideTypeSell).
+			Quantity(floatToBinancePrice(quantity)).
+			Price(floatToBinancePrice(priceConverted)).
+			StopPrice(floatToBinancePrice(stopPriceConverted)).
+			StopLimitPrice(floatToBinancePrice(stopLimitPriceConverted)).
+			StopLimitTimeInForce(binance.TimeInForceTypeGTC).
+			Do(context.Background())
+
+		if err != nil {
+			fmt.Println(err)
+			panic(err)
+		}
+
+		var limitOrderId, stopOrderId int64
+		for _, report := range order.OrderReports {
+			if report.Type == binance.OrderTypeStopLossLimit {
+				stopOrderId = report.OrderID
+			} else {
+				limitOrderId = report.OrderID
+			}
+		}
+
+		return limitOrderId, stopOrderId
+	}
+
+	return 0, 0
+}
+
 func (manager *OrderManager) CreateMarketSellOrder(symbol string, stopPrice, quantity float64) int64 {
 	if !manager.isEnabled {
 		return 0
@@ -261,6 +308,26 @@ func (manager *OrderManager) CreateMarketSellOrder(symbol string, stopPrice, qua
 	return 0
 }
 
+// Take profit ladder, one limit order per step
+func (manager *OrderManager) CreateLadderSellOrders(symbol string, prices []float64, quantity float64) ([]int64, []float64) {
+	if !manager.isEnabled {
+		return nil, nil
+	}
+
+	info, hasLotSize := manager.exchangeInfo.GetInfoForSymbol(symbol)
+	if !hasLotSize {
+		return nil, nil
+	}
+
+	var orderIds []int64
+	quantities := SplitQuantityToLotSize(quantity, info.LotSize.stepSize)
+	for step, price := range prices {
+		orderIds = append(orderIds, manager.CreateSellOrder(symbol, price, quantities[step]))
+	}
+
+	return orderIds, quantities
+}
+
 func (manager *OrderManager) CancelOrder(symbol string, orderId int64) int64 {
 	if !manager.isEnabled {
 		return 0
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"time"
 )
 
 type PlotData struct {
@@ -20,7 +21,7 @@ type TrailingSellPlotData struct {
 
 var plots map[int64]*PlotData
 
-func PlotAddBuy(buyId int64, buyTime string) {
+func PlotAddBuy(buyId int64, buyTime time.Time) {
 	if !canPlot() {
 		return
 	}
@@ -29,28 +30,28 @@ func PlotAddBuy(buyId int64, buyTime string) {
 
 	plots[buyId] = &PlotData{
 		BuyId:   buyId,
-		BuyTime: buyTime,
+		BuyTime: FormatTime(buyTime),
 	}
 }
 
-func PlotAddSell(buyId int64, sellTime string) {
+func PlotAddSell(buyId int64, sellTime time.Time) {
 	if !canPlot() {
 		return
 	}
 
 	if item, ok := plots[buyId]; ok {
-		item.SellTime = sellTime
+		item.SellTime = FormatTime(sellTime)
 	}
 }
 
-func PlotAddTrailingSellPoint(buyId int64, time string, stopPrice float64) {
+func PlotAddTrailingSellPoint(buyId int64, pointTime time.Time, stopPrice float64) {
 	if !canPlot() {
 		return
 	}
 
 	if item, ok := plots[buyId]; ok {
 		item.TrailingSell = append(item.TrailingSell, TrailingSellPlotData{
-			Time:      time,
+			Time:      FormatTime(pointTime),
 			StopPrice: stopPrice,
 		})
 	}
@@ -81,5 +82,5 @@ func initPlots() {
 }
 
 func canPlot() bool {
-	return 1 == BOTS_COUNT && 1 == GENERATION_COUNT
+	return BOTS_COUNT == 1 && GENERATION_COUNT == 1
 }
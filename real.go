@@ -48,6 +48,76 @@ func GetRealBotConfig() Config {
 
 		TotalMoneyAmount: 1000,
 		Leverage:         10,
+
+		RsiPeriod:             14,
+		RsiOversold:           30,
+		MacdFastPeriod:        12,
+		MacdSlowPeriod:        26,
+		MacdSignalPeriod:      9,
+		BollingerPeriod:       20,
+		BollingerDeviation:    2,
+		StochasticFastKPeriod: 14,
+		StochasticSlowKPeriod: 3,
+		StochasticSlowDPeriod: 3,
+		StochasticOversold:    20,
+
+		VolumeSpikePeriod:       48,
+		VolumeSpikeMultiplier:   2.5,
+		TakerImbalancePeriod:    3,
+		TakerSellPercentage:     60,
+		VwapPeriod:              48,
+		VwapDeviationPercentage: 1.5,
+		ObvPeriod:               12,
+
+		BuySignalExpression:     0,
+		BuySignalVoteCount:      2,
+		BuySignalScoreThreshold: 0.5,
+
+		StopLossMode:              StopLossPercentageMode,
+		StopLossPercentage:        10,
+		StopLossAtrPeriod:         14,
+		StopLossAtrMultiplier:     3,
+		StopLossMaxHoldingMinutes: 60 * 24 * 14,
+
+		DesiredPriceStrategy:      FixedDesiredPriceStrategy,
+		DesiredPriceAtrPeriod:     14,
+		DesiredPriceAtrMultiplier: 3,
+		DesiredPriceSwingCandles:  48,
+
+		HigherTrendTimeframe:    0,
+		HigherTrendEmaPeriod:    20,
+		HigherTrendSlopeCandles: 2,
+
+		DcaEnabled:                  0,
+		DcaSafetyOrdersCount:        0,
+		DcaPriceDeviationPercentage: 1.5,
+		DcaVolumeScale:              1.5,
+		DcaStepScale:                1.2,
+
+		RegimeAdxPeriod:           14,
+		RegimeAdxThreshold:        25,
+		RegimeMaPeriod:            50,
+		RegimeSlopeCandles:        12,
+		RegimeVolatilityPeriod:    48,
+		RegimeVolatilityThreshold: 0.8,
+		RegimeMask:                1 << RegimeRanging,
+
+		CircuitBreakerLossesCount:        3,
+		CircuitBreakerLiquidationsCount:  1,
+		CircuitBreakerDrawdownPercentage: 10,
+		CircuitBreakerCooldownMinutes:    60 * 24,
+
+		TradingWindowStartHour:  0,
+		TradingWindowHoursCount: 24,
+		TradingWeekdaysMask:     1<<7 - 1,
+
+		ProfitLockActivationPercentage: 0.5,
+		ProfitLockStepPercentage:       0.5,
+
+		DecayTakeProfitMode:            0,
+		DecayTakeProfitMinutes:         60 * 24,
+		DecayTakeProfitFloorPercentage: 0.0,
+		DecayTakeProfitRate:            3.0,
 	}
 }
 
@@ -56,6 +126,7 @@ func RunRealTime() {
 	tgBot, _ = tgbotapi.NewBotAPI(TG_API_KEY)
 
 	candleConverter = NewSecToMinCandleConverter()
+	InitKlineRecorder()
 	config := GetRealBotConfig()
 	realBot = NewRealBot(&config, client)
 
@@ -79,8 +150,12 @@ func RunRealTime() {
 }
 
 func KlineEventHandler(event *binance.WsKlineEvent) {
+	if klineRecorder != nil {
+		klineRecorder.RecordSpot(event, time.Now())
+	}
+
 	secCandle := WebSocketCandleToKlineCandle(event.Kline)
-	fmt.Println(fmt.Sprintf("%s - Coin: %s, Price: %f", secCandle.CloseTime, secCandle.Symbol, secCandle.ClosePrice))
+	fmt.Println(fmt.Sprintf("%s - Coin: %s, Price: %f", FormatTime(secCandle.CloseTime), secCandle.Symbol, secCandle.ClosePrice))
 
 	if convertedCandle, ok := candleConverter.Convert(secCandle); ok {
 		realBot.DoStuff(convertedCandle)
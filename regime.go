@@ -0,0 +1,119 @@
+package main
+
+import (
+	"github.com/markcheno/go-talib"
+	"math"
+)
+
+type Regime int
+
+const (
+	RegimeUnknown        Regime = -1
+	RegimeRanging        Regime = 0
+	RegimeTrendingUp     Regime = 1
+	RegimeTrendingDown   Regime = 2
+	RegimeHighVolatility Regime = 3
+	RegimesCount                = 4
+)
+
+func (regime Regime) String() string {
+	switch regime {
+	case RegimeRanging:
+		return "ranging"
+	case RegimeTrendingUp:
+		return "trending-up"
+	case RegimeTrendingDown:
+		return "trending-down"
+	case RegimeHighVolatility:
+		return "high-volatility"
+	}
+
+	return "unknown"
+}
+
+// Labels the last candle. High volatility goes first, then a strong ADX is a trend in the direction of
+// the moving average slope, everything else is a range.
+type RegimeClassifier struct {
+	config *Config
+	buffer *Buffer
+	sma    *StreamingSma
+}
+
+// Every bot labels its buys, so a config without the regime genes only gets unknown regimes
+func NewRegimeClassifier(config *Config, buffer *Buffer) RegimeClassifier {
+	classifier := RegimeClassifier{
+		config: config,
+		buffer: buffer,
+	}
+
+	if config.RegimeMaPeriod >= 1 {
+		classifier.sma = buffer.GetSma(config.RegimeMaPeriod, config.RegimeSlopeCandles+1)
+	}
+
+	return classifier
+}
+
+func (classifier *RegimeClassifier) Classify() Regime {
+	adx, slope, volatility, ok := classifier.calcValues()
+	if !ok {
+		return RegimeUnknown
+	}
+
+	if volatility >= classifier.config.RegimeVolatilityThreshold {
+		return RegimeHighVolatility
+	}
+
+	if adx >= classifier.config.RegimeAdxThreshold {
+		if slope > 0 {
+			return RegimeTrendingUp
+		}
+
+		return RegimeTrendingDown
+	}
+
+	return RegimeRanging
+}
+
+// ADX, slope of the moving average in percents and standard deviation of the log returns in percents
+func (classifier *RegimeClassifier) calcValues() (float64, float64, float64, bool) {
+	if classifier.sma == nil {
+		return 0, 0, 0, false
+	}
+
+	candles := classifier.buffer.GetCandles()
+	count := len(candles)
+	smoothedPrices := classifier.sma.GetValues()
+
+	if classifier.config.RegimeAdxPeriod < 1 ||
+		classifier.config.RegimeAdxPeriod*2 > count ||
+		(classifier.config.RegimeVolatilityPeriod+1) > count ||
+		(classifier.config.RegimeSlopeCandles+1) > len(smoothedPrices) {
+		return 0, 0, 0, false
+	}
+
+	adx := talib.Adx(
+		GetHighPrices(candles),
+		GetLowPrices(candles),
+		GetClosePrices(candles),
+		classifier.config.RegimeAdxPeriod,
+	)
+	slope := CalcGrowth(smoothedPrices[0], smoothedPrices[len(smoothedPrices)-1])
+
+	var returns []float64
+	closePrices := GetClosePrices(candles[count-classifier.config.RegimeVolatilityPeriod-1:])
+	for i := 1; i < len(closePrices); i++ {
+		returns = append(returns, math.Log(closePrices[i]/closePrices[i-1])*100)
+	}
+
+	return adx[len(adx)-1], slope, calcStdDev(returns), true
+}
+
+func calcStdDev(values []float64) float64 {
+	avg := GetAvg(values)
+	total := 0.0
+	for _, value := range values {
+		total += (value - avg) * (value - avg)
+	}
+
+	return math.Sqrt(total / float64(len(values)))
+}
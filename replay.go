@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+type ReplayClock struct {
+	speed       float64
+	startedAt   time.Time
+	firstRecord int64
+	isStarted   bool
+}
+
+func NewReplayClock(speed float64) ReplayClock {
+	return ReplayClock{speed: speed}
+}
+
+// Sleeps until the record is due, zero speed replays as fast as possible
+func (clock *ReplayClock) WaitFor(receivedAt int64) {
+	if clock.speed <= 0 {
+		return
+	}
+
+	if !clock.isStarted {
+		clock.isStarted = true
+		clock.startedAt = time.Now()
+		clock.firstRecord = receivedAt
+		return
+	}
+
+	recordOffset := time.Duration(receivedAt-clock.firstRecord) * time.Millisecond
+	dueAt := clock.startedAt.Add(time.Duration(float64(recordOffset) / clock.speed))
+
+	if wait := time.Until(dueAt); wait > 0 {
+		time.Sleep(wait)
+	}
+}
+
+func RunReplay() {
+	LogAndPrint(fmt.Sprintf("Replay has started: %s, Speed: %f", REPLAY_RECORDS_DB_NAME, REPLAY_SPEED))
+
+	if err := os.MkdirAll("db", 0755); err != nil {
+		panic(err)
+	}
+
+	candleConverter = NewSecToMinCandleConverter()
+	config := GetRealBotConfig()
+	realBot = NewBot(&config)
+
+	market := SPOT_MARKET
+	if ENABLE_FUTURES {
+		market = FUTURES_MARKET
+	}
+
+	klines := OpenRecordedKlines(REPLAY_RECORDS_DB_NAME, market, CANDLE_SYMBOL, CANDLE_INTERVAL)
+	clock := NewReplayClock(REPLAY_SPEED)
+
+	for {
+		kline, ok := klines.Next()
+		if !ok {
+			break
+		}
+
+		clock.WaitFor(kline.ReceivedAt)
+
+		if ENABLE_FUTURES {
+			KlineEventHandlerFutures(kline.ToFuturesEvent())
+		} else {
+			KlineEventHandler(kline.ToSpotEvent())
+		}
+	}
+
+	klines.Close()
+
+	revenue, buysCount, unsoldBuysCount, liquidationCount, avgSellTime := calcBotResults(&realBot)
+	realBot.Kill()
+
+	LogAndPrint(fmt.Sprintf(
+		"Replay has finished\nRevenue: %f\nBuysCount: %d\nUnsoldBuysCount: %d\nLiquidationCount: %d\nAvgSellTime: %f",
+		revenue,
+		buysCount,
+		unsoldBuysCount,
+		liquidationCount,
+		avgSellTime,
+	))
+}
@@ -1,6 +1,10 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+	"time"
+)
 
 type SellIndicator interface {
 	HasSignal() (bool, []Buy)
@@ -50,6 +54,17 @@ func (indicator *HighPercentageSellIndicator) Update() {
 func (indicator *HighPercentageSellIndicator) Finish(buyId int64) {
 }
 
+func (indicator *HighPercentageSellIndicator) GetTraceValues() []TraceValue {
+	upperPrices := map[int64]float64{}
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		if buy.PositionId == 0 {
+			upperPrices[buy.Id] = CalcUpperPrice(buy.ExchangeRate, indicator.config.HighSellPercentage)
+		}
+	}
+
+	return buyIdsTraceValues("upper_price", upperPrices)
+}
+
 // ------------------------------------
 
 type DesiredPriceSellIndicator struct {
@@ -87,6 +102,15 @@ func (indicator *DesiredPriceSellIndicator) Update() {
 func (indicator *DesiredPriceSellIndicator) Finish(buyId int64) {
 }
 
+func (indicator *DesiredPriceSellIndicator) GetTraceValues() []TraceValue {
+	desiredPrices := map[int64]float64{}
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		desiredPrices[buy.Id] = buy.DesiredPrice
+	}
+
+	return buyIdsTraceValues("desired_price", desiredPrices)
+}
+
 // ------------------------------------
 
 type TrailingBuy struct {
@@ -215,6 +239,60 @@ func (indicator *TrailingSellIndicator) calculateStopPrice(closePrice, percentag
 	return closePrice - ((closePrice * percentage) / 100)
 }
 
+func (indicator *TrailingSellIndicator) GetTraceValues() []TraceValue {
+	stopPrices := map[int64]float64{}
+	for buyId, buyItem := range indicator.buys {
+		if buyItem.isActivated {
+			stopPrices[buyId] = buyItem.stopPrice
+		}
+	}
+
+	return buyIdsTraceValues("stop_price", stopPrices)
+}
+
+type trailingBuyState struct {
+	IsActivated bool    `json:"is_activated"`
+	BuyPrice    float64 `json:"buy_price"`
+	StopPrice   float64 `json:"stop_price"`
+}
+
+func (indicator *TrailingSellIndicator) GetStateName() string {
+	return "Trailing"
+}
+
+func (indicator *TrailingSellIndicator) GetStateVersion() int {
+	return 1
+}
+
+func (indicator *TrailingSellIndicator) SaveState() string {
+	state := map[int64]trailingBuyState{}
+	for buyId, buyItem := range indicator.buys {
+		state[buyId] = trailingBuyState{
+			IsActivated: buyItem.isActivated,
+			BuyPrice:    buyItem.buyPrice,
+			StopPrice:   buyItem.stopPrice,
+		}
+	}
+
+	return marshalIndicatorState(state)
+}
+
+// Buys sold while the bot was down are skipped
+func (indicator *TrailingSellIndicator) RestoreState(encoded string) {
+	state := map[int64]trailingBuyState{}
+	unmarshalIndicatorState(encoded, &state)
+
+	for _, unsoldBuy := range indicator.db.FetchUnsoldBuys() {
+		if buyState, ok := state[unsoldBuy.Id]; ok {
+			indicator.buys[unsoldBuy.Id] = &TrailingBuy{
+				isActivated: buyState.IsActivated,
+				buyPrice:    buyState.BuyPrice,
+				stopPrice:   buyState.StopPrice,
+			}
+		}
+	}
+}
+
 // ------------------------------------
 
 type LeverageSellIndicator struct {
@@ -246,6 +324,10 @@ func (indicator *LeverageSellIndicator) HasSignal() (bool, []Buy) {
 	)
 	indicator.appendBuyIfNotExists(&resultingBuys, upperBuys)
 
+	// DCA positions are closed together at the take profit of the average entry price
+	positionBuys := indicator.db.FetchUnsoldPositionBuysByDesiredPrice(candle.GetPrice())
+	indicator.appendBuyIfNotExists(&resultingBuys, positionBuys)
+
 	// Liquidation buys
 	liquidationBuys := indicator.db.FetchUnsoldBuysByLowerPercentage(
 		candle.LowPrice,
@@ -289,8 +371,6 @@ func (indicator *LeverageSellIndicator) markBuys(
 			(*targetList)[idx].BuyType = buyType
 		}
 	}
-
-	fmt.Println()
 }
 
 func (indicator *LeverageSellIndicator) hasSuchBuy(buyId int64, buys []Buy) bool {
@@ -311,3 +391,581 @@ func (indicator *LeverageSellIndicator) Update() {
 
 func (indicator *LeverageSellIndicator) Finish(buyId int64) {
 }
+
+// DCA position layers are sold at the desired price of the average entry, the other buys at the upper price
+func (indicator *LeverageSellIndicator) GetTraceValues() []TraceValue {
+	upperPrices := map[int64]float64{}
+	desiredPrices := map[int64]float64{}
+	liquidationPrices := map[int64]float64{}
+
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		if buy.PositionId == 0 {
+			upperPrices[buy.Id] = CalcUpperPrice(buy.ExchangeRate, indicator.config.HighSellPercentage)
+		} else {
+			desiredPrices[buy.Id] = buy.DesiredPrice
+		}
+
+		liquidationPrices[buy.Id] = CalcBottomPrice(
+			buy.ExchangeRate,
+			GetLeverageLiquidationPercentage(indicator.config.Leverage),
+		)
+	}
+
+	values := buyIdsTraceValues("upper_price", upperPrices)
+	values = append(values, buyIdsTraceValues("desired_price", desiredPrices)...)
+
+	return append(values, buyIdsTraceValues("liquidation_price", liquidationPrices)...)
+}
+
+// ------------------------------------
+
+const (
+	StopLossPercentageMode  = 0
+	StopLossAtrMode         = 1
+	StopLossHoldingTimeMode = 2
+)
+
+type StopLossSellIndicator struct {
+	config     *Config
+	buffer     *Buffer
+	db         *Database
+	atr        *StreamingAtr
+	stopPrices map[int64]float64
+}
+
+func NewStopLossSellIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) StopLossSellIndicator {
+	indicator := StopLossSellIndicator{
+		config:     config,
+		buffer:     buffer,
+		db:         db,
+		stopPrices: map[int64]float64{},
+	}
+
+	if config.StopLossMode == StopLossAtrMode {
+		indicator.atr = buffer.GetAtr(config.StopLossAtrPeriod)
+	}
+
+	return indicator
+}
+
+func (indicator *StopLossSellIndicator) HasSignal() (bool, []Buy) {
+	var resultingBuys []Buy
+	candle := indicator.buffer.GetLastCandle()
+
+	if indicator.config.StopLossMode == StopLossHoldingTimeMode {
+		for _, buy := range indicator.db.FetchTimeCancelBuys(candle.CloseTime, indicator.config.StopLossMaxHoldingMinutes) {
+			buy.BuyType = StopLoss
+			buy.ExitPrice = candle.ClosePrice
+			resultingBuys = append(resultingBuys, buy)
+		}
+
+		return len(resultingBuys) > 0, resultingBuys
+	}
+
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		stopPrice := indicator.GetStopPrice(buy)
+		if candle.LowPrice > stopPrice {
+			continue
+		}
+
+		// A gap down fills below the stop price
+		buy.BuyType = StopLoss
+		buy.ExitPrice = Min([]float64{stopPrice, candle.OpenPrice})
+		resultingBuys = append(resultingBuys, buy)
+	}
+
+	return len(resultingBuys) > 0, resultingBuys
+}
+
+func (indicator *StopLossSellIndicator) GetStopPrice(buy Buy) float64 {
+	if stopPrice, ok := indicator.stopPrices[buy.Id]; ok {
+		return stopPrice
+	}
+
+	// Buys made before a restart get the stop from the current candles
+	stopPrice := indicator.calcStopPrice(buy.ExchangeRate)
+	indicator.stopPrices[buy.Id] = stopPrice
+
+	return stopPrice
+}
+
+// The exchange stop order is useless when the position is closed by time
+func (indicator *StopLossSellIndicator) HasStopPrice() bool {
+	return indicator.config.StopLossMode != StopLossHoldingTimeMode
+}
+
+func (indicator *StopLossSellIndicator) calcStopPrice(buyPrice float64) float64 {
+	if indicator.config.StopLossMode == StopLossAtrMode && indicator.atr.IsReady() {
+		stopPrice := buyPrice - indicator.atr.Last()*indicator.config.StopLossAtrMultiplier
+		return math.Max(stopPrice, CalcBottomPrice(buyPrice, STOP_LOSS_MAX_PERCENTAGE))
+	}
+
+	return CalcBottomPrice(buyPrice, indicator.config.StopLossPercentage)
+}
+
+func (indicator *StopLossSellIndicator) RunAfterBuy(buyId int64) {
+	indicator.stopPrices[buyId] = indicator.calcStopPrice(indicator.buffer.GetLastCandleClosePrice())
+}
+
+func (indicator *StopLossSellIndicator) Update() {
+}
+
+func (indicator *StopLossSellIndicator) Finish(buyId int64) {
+	delete(indicator.stopPrices, buyId)
+}
+
+func (indicator *StopLossSellIndicator) GetTraceValues() []TraceValue {
+	return buyIdsTraceValues("stop_price", indicator.stopPrices)
+}
+
+func (indicator *StopLossSellIndicator) GetStateName() string {
+	return "StopLoss"
+}
+
+func (indicator *StopLossSellIndicator) GetStateVersion() int {
+	return 1
+}
+
+func (indicator *StopLossSellIndicator) SaveState() string {
+	return marshalIndicatorState(indicator.stopPrices)
+}
+
+func (indicator *StopLossSellIndicator) RestoreState(encoded string) {
+	stopPrices := map[int64]float64{}
+	unmarshalIndicatorState(encoded, &stopPrices)
+
+	for _, unsoldBuy := range indicator.db.FetchUnsoldBuys() {
+		if stopPrice, ok := stopPrices[unsoldBuy.Id]; ok {
+			indicator.stopPrices[unsoldBuy.Id] = stopPrice
+		}
+	}
+}
+
+// ------------------------------------
+
+// Partial take profit, sells the next step of TAKE_PROFIT_LADDER when its target is reached.
+// DCA position layers are sold together, so they are left to the other indicators
+type TakeProfitLadderSellIndicator struct {
+	config *Config
+	buffer *Buffer
+	db     *Database
+}
+
+func NewTakeProfitLadderSellIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) TakeProfitLadderSellIndicator {
+	return TakeProfitLadderSellIndicator{
+		config: config,
+		buffer: buffer,
+		db:     db,
+	}
+}
+
+func (indicator *TakeProfitLadderSellIndicator) HasSignal() (bool, []Buy) {
+	var resultingBuys []Buy
+	if !IsTakeProfitLadderEnabled() {
+		return false, resultingBuys
+	}
+
+	candle := indicator.buffer.GetLastCandle()
+	maxPrice := Max([]float64{candle.ClosePrice, candle.HighPrice})
+
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		step, ok := GetNextTakeProfitStep(buy)
+		if !ok || buy.PositionId != 0 {
+			continue
+		}
+
+		stepPrice := CalcTakeProfitStepPrice(buy, step)
+		if maxPrice < stepPrice {
+			continue
+		}
+
+		buy.ExitPrice = stepPrice
+		buy.SellCoins = CalcTakeProfitStepCoins(buy, step)
+		buy.RealQuantity = buy.RealQuantity * buy.SellCoins / buy.Coins
+
+		// Live steps are filled by their own limit orders, buys without them keep the single sell order
+		if USE_REAL_MONEY {
+			orders := indicator.db.FetchTakeProfitOrders(buy.Id)
+			if len(orders) == 0 || orders[0].Step != step {
+				continue
+			}
+
+			buy.RealOrderId = orders[0].OrderId
+			buy.RealQuantity = orders[0].Quantity
+		}
+
+		resultingBuys = append(resultingBuys, buy)
+	}
+
+	return len(resultingBuys) > 0, resultingBuys
+}
+
+func (indicator *TakeProfitLadderSellIndicator) RunAfterBuy(buyId int64) {
+}
+
+func (indicator *TakeProfitLadderSellIndicator) Update() {
+}
+
+func (indicator *TakeProfitLadderSellIndicator) Finish(buyId int64) {
+}
+
+func (indicator *TakeProfitLadderSellIndicator) GetTraceValues() []TraceValue {
+	steps := map[int64]float64{}
+	stepPrices := map[int64]float64{}
+
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		step, ok := GetNextTakeProfitStep(buy)
+		if !ok || buy.PositionId != 0 {
+			continue
+		}
+
+		steps[buy.Id] = float64(step)
+		stepPrices[buy.Id] = CalcTakeProfitStepPrice(buy, step)
+	}
+
+	return append(buyIdsTraceValues("step", steps), buyIdsTraceValues("step_price", stepPrices)...)
+}
+
+// ------------------------------------
+
+// Raises the stop of a buy to the break-even price (entry plus the buy and sell commission) once the price has
+// grown by ProfitLockActivationPercentage, then by ProfitLockStepPercentage for every further step of the growth.
+// The stop is above the entry, so a locked futures position is closed before the liquidation.
+// DCA position layers are sold together, so they are not locked
+type ProfitLockBuy struct {
+	buyPrice  float64
+	stopPrice float64
+	isStopped bool
+}
+
+type ProfitLockSellIndicator struct {
+	config     *Config
+	buffer     *Buffer
+	db         *Database
+	buys       map[int64]*ProfitLockBuy
+	movedStops map[int64]float64
+}
+
+func NewProfitLockSellIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) ProfitLockSellIndicator {
+	return ProfitLockSellIndicator{
+		config:     config,
+		buffer:     buffer,
+		db:         db,
+		buys:       map[int64]*ProfitLockBuy{},
+		movedStops: map[int64]float64{},
+	}
+}
+
+func (indicator *ProfitLockSellIndicator) RunAfterBuy(buyId int64) {
+	indicator.buys[buyId] = &ProfitLockBuy{
+		buyPrice: indicator.buffer.GetLastCandleClosePrice(),
+	}
+}
+
+// The candle is checked against the stop of the previous candles, the new stop applies from the next one
+func (indicator *ProfitLockSellIndicator) Update() {
+	candle := indicator.buffer.GetLastCandle()
+
+	for _, unsoldBuy := range indicator.db.FetchUnsoldBuys() {
+		buyItem, ok := indicator.buys[unsoldBuy.Id]
+		if !ok || unsoldBuy.PositionId != 0 {
+			continue
+		}
+
+		buyItem.isStopped = buyItem.stopPrice > 0 && candle.LowPrice <= buyItem.stopPrice
+		if buyItem.isStopped {
+			continue
+		}
+
+		stopPrice := indicator.calcStopPrice(buyItem.buyPrice, candle.ClosePrice)
+		if stopPrice <= buyItem.stopPrice {
+			continue
+		}
+
+		buyItem.stopPrice = stopPrice
+		indicator.movedStops[unsoldBuy.Id] = stopPrice
+
+		Log(fmt.Sprintf("ProfitLockSellIndicator__STOP_MOVED\nBuyId: %d\nStopPrice: %f", unsoldBuy.Id, stopPrice))
+		PlotAddTrailingSellPoint(unsoldBuy.Id, candle.CloseTime, stopPrice)
+	}
+}
+
+// 0 until the activation
+func (indicator *ProfitLockSellIndicator) calcStopPrice(buyPrice, currentPrice float64) float64 {
+	growth := CalcGrowth(buyPrice, currentPrice)
+	if growth < indicator.config.ProfitLockActivationPercentage {
+		return 0
+	}
+
+	steps := math.Floor((growth - indicator.config.ProfitLockActivationPercentage) / indicator.config.ProfitLockStepPercentage)
+
+	return CalcUpperPrice(buyPrice, COMMISSION*2+steps*indicator.config.ProfitLockStepPercentage)
+}
+
+func (indicator *ProfitLockSellIndicator) HasSignal() (bool, []Buy) {
+	var resultingBuys []Buy
+	var lockedBuyIds []int64
+	candle := indicator.buffer.GetLastCandle()
+
+	for buyId, buyItem := range indicator.buys {
+		if buyItem.isStopped {
+			lockedBuyIds = append(lockedBuyIds, buyId)
+		}
+	}
+
+	if len(lockedBuyIds) == 0 {
+		return false, resultingBuys
+	}
+
+	for _, buy := range indicator.db.FetchUnsoldBuysById(lockedBuyIds) {
+		buy.BuyType = StopLoss
+		buy.ExitPrice = Min([]float64{indicator.buys[buy.Id].stopPrice, candle.OpenPrice})
+		resultingBuys = append(resultingBuys, buy)
+	}
+
+	return len(resultingBuys) > 0, resultingBuys
+}
+
+func (indicator *ProfitLockSellIndicator) GetStopPrice(buyId int64) (float64, bool) {
+	if buyItem, ok := indicator.buys[buyId]; ok && buyItem.stopPrice > 0 {
+		return buyItem.stopPrice, true
+	}
+
+	return 0, false
+}
+
+// Stops moved since the last call, the bot moves their exchange orders
+func (indicator *ProfitLockSellIndicator) PopMovedStops() map[int64]float64 {
+	movedStops := indicator.movedStops
+	indicator.movedStops = map[int64]float64{}
+
+	return movedStops
+}
+
+func (indicator *ProfitLockSellIndicator) Finish(buyId int64) {
+	delete(indicator.buys, buyId)
+	delete(indicator.movedStops, buyId)
+}
+
+func (indicator *ProfitLockSellIndicator) GetTraceValues() []TraceValue {
+	stopPrices := map[int64]float64{}
+	for buyId, buyItem := range indicator.buys {
+		if buyItem.stopPrice > 0 {
+			stopPrices[buyId] = buyItem.stopPrice
+		}
+	}
+
+	return buyIdsTraceValues("stop_price", stopPrices)
+}
+
+type profitLockBuyState struct {
+	BuyPrice  float64 `json:"buy_price"`
+	StopPrice float64 `json:"stop_price"`
+}
+
+func (indicator *ProfitLockSellIndicator) GetStateName() string {
+	return "ProfitLock"
+}
+
+func (indicator *ProfitLockSellIndicator) GetStateVersion() int {
+	return 1
+}
+
+func (indicator *ProfitLockSellIndicator) SaveState() string {
+	state := map[int64]profitLockBuyState{}
+	for buyId, buyItem := range indicator.buys {
+		state[buyId] = profitLockBuyState{
+			BuyPrice:  buyItem.buyPrice,
+			StopPrice: buyItem.stopPrice,
+		}
+	}
+
+	return marshalIndicatorState(state)
+}
+
+func (indicator *ProfitLockSellIndicator) RestoreState(encoded string) {
+	state := map[int64]profitLockBuyState{}
+	unmarshalIndicatorState(encoded, &state)
+
+	for _, unsoldBuy := range indicator.db.FetchUnsoldBuys() {
+		if buyState, ok := state[unsoldBuy.Id]; ok {
+			indicator.buys[unsoldBuy.Id] = &ProfitLockBuy{
+				buyPrice:  buyState.BuyPrice,
+				stopPrice: buyState.StopPrice,
+			}
+		}
+	}
+}
+
+// ------------------------------------
+
+const (
+	DecayLinearMode      = 0
+	DecayExponentialMode = 1
+)
+
+// Smooth time exit, the take profit of a buy decays from HighSellPercentage to the break-even price
+// (entry plus the buy and sell commission) moved by DecayTakeProfitFloorPercentage during DecayTakeProfitMinutes.
+// The exponential decay drops fast at first, DecayTakeProfitRate is its steepness.
+// DCA position layers are sold together, so they keep the position take profit
+type DecayingTakeProfitSellIndicator struct {
+	config      *Config
+	buffer      *Buffer
+	db          *Database
+	orderPrices map[int64]float64
+	movedPrices map[int64]float64
+}
+
+func NewDecayingTakeProfitSellIndicator(
+	config *Config,
+	buffer *Buffer,
+	db *Database,
+) DecayingTakeProfitSellIndicator {
+	return DecayingTakeProfitSellIndicator{
+		config:      config,
+		buffer:      buffer,
+		db:          db,
+		orderPrices: map[int64]float64{},
+		movedPrices: map[int64]float64{},
+	}
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) HasSignal() (bool, []Buy) {
+	var resultingBuys []Buy
+	candle := indicator.buffer.GetLastCandle()
+	maxPrice := Max([]float64{candle.ClosePrice, candle.HighPrice})
+
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		if buy.PositionId != 0 {
+			continue
+		}
+
+		targetPrice := indicator.CalcTargetPrice(buy, candle.CloseTime)
+		if orderPrice, ok := indicator.orderPrices[buy.Id]; ok {
+			targetPrice = orderPrice
+		}
+
+		if maxPrice < targetPrice {
+			continue
+		}
+
+		buy.ExitPrice = targetPrice
+		resultingBuys = append(resultingBuys, buy)
+	}
+
+	return len(resultingBuys) > 0, resultingBuys
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) CalcTargetPrice(buy Buy, currentTime time.Time) float64 {
+	return CalcUpperPrice(buy.ExchangeRate, indicator.calcTargetPercentage(currentTime.Sub(buy.CreatedAt).Minutes()))
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) calcTargetPercentage(ageMinutes float64) float64 {
+	floorPercentage := COMMISSION*2 + indicator.config.DecayTakeProfitFloorPercentage
+	progress := math.Min(math.Max(ageMinutes/float64(indicator.config.DecayTakeProfitMinutes), 0), 1)
+
+	left := 1 - progress
+	rate := indicator.config.DecayTakeProfitRate
+	// The exponential decay with a zero rate is the linear one
+	if indicator.config.DecayTakeProfitMode == DecayExponentialMode && rate != 0 {
+		left = (math.Exp(-rate*progress) - math.Exp(-rate)) / (1 - math.Exp(-rate))
+	}
+
+	return floorPercentage + (indicator.config.HighSellPercentage-floorPercentage)*left
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) RunAfterBuy(buyId int64) {
+}
+
+// Live orders follow the target, buys without their own take profit order (ladders, DCA layers) are skipped
+func (indicator *DecayingTakeProfitSellIndicator) Update() {
+	if !IS_REAL_ENABLED || !USE_REAL_MONEY {
+		return
+	}
+
+	candle := indicator.buffer.GetLastCandle()
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		if buy.PositionId != 0 || buy.HasSellOrder == 0 {
+			continue
+		}
+
+		orderPrice, ok := indicator.orderPrices[buy.Id]
+		if !ok {
+			orderPrice = buy.DesiredPrice
+		}
+
+		targetPrice := indicator.CalcTargetPrice(buy, candle.CloseTime)
+		if math.Abs(CalcGrowth(orderPrice, targetPrice)) < DECAY_TAKE_PROFIT_ORDER_STEP_PERCENTAGE {
+			continue
+		}
+
+		indicator.orderPrices[buy.Id] = targetPrice
+		indicator.movedPrices[buy.Id] = targetPrice
+	}
+}
+
+// Targets moved since the last call, the bot replaces their exchange orders
+func (indicator *DecayingTakeProfitSellIndicator) PopMovedPrices() map[int64]float64 {
+	movedPrices := indicator.movedPrices
+	indicator.movedPrices = map[int64]float64{}
+
+	return movedPrices
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) GetOrderPrice(buyId int64) (float64, bool) {
+	orderPrice, ok := indicator.orderPrices[buyId]
+
+	return orderPrice, ok
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) Finish(buyId int64) {
+	delete(indicator.orderPrices, buyId)
+	delete(indicator.movedPrices, buyId)
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) GetTraceValues() []TraceValue {
+	candle := indicator.buffer.GetLastCandle()
+	targetPrices := map[int64]float64{}
+	for _, buy := range indicator.db.FetchUnsoldBuys() {
+		if buy.PositionId == 0 {
+			targetPrices[buy.Id] = indicator.CalcTargetPrice(buy, candle.CloseTime)
+		}
+	}
+
+	return buyIdsTraceValues("target_price", targetPrices)
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) GetStateName() string {
+	return "DecayingTakeProfit"
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) GetStateVersion() int {
+	return 1
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) SaveState() string {
+	return marshalIndicatorState(indicator.orderPrices)
+}
+
+func (indicator *DecayingTakeProfitSellIndicator) RestoreState(encoded string) {
+	orderPrices := map[int64]float64{}
+	unmarshalIndicatorState(encoded, &orderPrices)
+
+	for _, unsoldBuy := range indicator.db.FetchUnsoldBuys() {
+		if orderPrice, ok := orderPrices[unsoldBuy.Id]; ok {
+			indicator.orderPrices[unsoldBuy.Id] = orderPrice
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// The buy is made at the close of the first candle, the signals are checked on the candles after it
+type sellIndicatorTest struct {
+	name     string
+	config   func(config *Config)
+	candles  []Candle
+	afterBuy func(db *Database, buy Buy)
+	expected [][]int64
+	check    func(t *testing.T, buys []Buy)
+}
+
+func runSellIndicatorTests(t *testing.T, name string, tests []sellIndicatorTest) {
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runTestModes(t, func(t *testing.T) {
+				config := newTestConfig()
+				if test.config != nil {
+					test.config(&config)
+				}
+
+				buffer := newTestBuffer(&config)
+				db := newTestDatabase(t, &config)
+				indicator := sellIndicatorConstructors[name](&config, buffer, db)
+
+				runTestSellIndicator(buffer, indicator, test.candles[:1])
+				buy := addTestBuy(db, buffer, indicator)
+				if test.afterBuy != nil {
+					test.afterBuy(db, buy)
+				}
+
+				signals := runTestSellIndicator(buffer, indicator, test.candles[1:])
+				if len(signals) != len(test.expected) {
+					t.Fatalf("expected %d signals, got %d", len(test.expected), len(signals))
+				}
+
+				for index, buys := range signals {
+					assertIds(t, fmt.Sprintf("candle %d", index), test.expected[index], buyIds(buys))
+				}
+
+				if test.check != nil {
+					test.check(t, signals[len(signals)-1])
+				}
+			})
+		})
+	}
+}
+
+// Buy ids of count candles, the buy is sold from the candle with the from index
+func sellSignalsFrom(count, from int, buyId int64) [][]int64 {
+	signals := make([][]int64, count)
+	for i := range signals {
+		signals[i] = []int64{}
+		if i >= from {
+			signals[i] = []int64{buyId}
+		}
+	}
+
+	return signals
+}
+
+func TestHighPercentageSellIndicator(t *testing.T) {
+	runSellIndicatorTests(t, "HighPercentage", []sellIndicatorTest{
+		{
+			name:     "reaches the percentage",
+			candles:  newTestPriceCandles(100, 100.5, 101.5),
+			expected: [][]int64{{}, {1}},
+		},
+		{
+			name:     "falls",
+			candles:  newTestPriceCandles(100, 99, 98),
+			expected: [][]int64{{}, {}},
+		},
+	})
+}
+
+func TestDesiredPriceSellIndicator(t *testing.T) {
+	runSellIndicatorTests(t, "DesiredPrice", []sellIndicatorTest{
+		{
+			name: "high price touches the desired price",
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 100, Low: 100, Close: 100},
+				{Open: 100, High: 100.8, Low: 100, Close: 100},
+				{Open: 100, High: 101.2, Low: 100, Close: 100},
+			}),
+			expected: [][]int64{{}, {1}},
+		},
+	})
+}
+
+func TestTrailingSellIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.TrailingSellActivationAdditionPercentage = 0.5
+		config.TrailingSellStopPercentage = 0.5
+	}
+
+	runSellIndicatorTests(t, "Trailing", []sellIndicatorTest{
+		{
+			name:     "stop follows the growth",
+			config:   config,
+			candles:  newTestPriceCandles(100, 101, 102, 103, 102.4),
+			expected: [][]int64{{}, {}, {}, {1}},
+		},
+		{
+			name:     "not activated",
+			config:   config,
+			candles:  newTestPriceCandles(100, 101, 101.4, 100),
+			expected: [][]int64{{}, {}, {}},
+		},
+	})
+}
+
+func TestLeverageSellIndicator(t *testing.T) {
+	runSellIndicatorTests(t, "Leverage", []sellIndicatorTest{
+		{
+			name:     "take profit",
+			candles:  newTestPriceCandles(100, 100.5, 101.5),
+			expected: [][]int64{{}, {1}},
+			check: func(t *testing.T, buys []Buy) {
+				if buys[0].BuyType != 0 {
+					t.Errorf("expected no buy type, got %d", buys[0].BuyType)
+				}
+			},
+		},
+		{
+			name:     "liquidation",
+			candles:  newTestPriceCandles(100, 99, 50),
+			expected: [][]int64{{}, {1}},
+			check: func(t *testing.T, buys []Buy) {
+				if buys[0].BuyType != Liquidation {
+					t.Errorf("expected liquidation, got %d", buys[0].BuyType)
+				}
+			},
+		},
+	})
+}
+
+func TestStopLossSellIndicator(t *testing.T) {
+	exitPrice := func(price float64) func(t *testing.T, buys []Buy) {
+		return func(t *testing.T, buys []Buy) {
+			if buys[0].BuyType != StopLoss {
+				t.Errorf("expected stop loss, got %d", buys[0].BuyType)
+			}
+			assertFloat(t, "exit price", price, buys[0].ExitPrice)
+		}
+	}
+
+	runSellIndicatorTests(t, "StopLoss", []sellIndicatorTest{
+		{
+			name: "low touches the stop",
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 100, Low: 100, Close: 100},
+				{Open: 100, High: 100, Low: 96, Close: 97},
+				{Open: 97, High: 97, Low: 94, Close: 96},
+			}),
+			expected: [][]int64{{}, {1}},
+			check:    exitPrice(95),
+		},
+		{
+			name: "gap down fills below the stop",
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 100, Low: 100, Close: 100},
+				{Open: 94, High: 94, Low: 93, Close: 93},
+			}),
+			expected: [][]int64{{1}},
+			check:    exitPrice(94),
+		},
+		{
+			name: "holding time",
+			config: func(config *Config) {
+				config.StopLossMode = StopLossHoldingTimeMode
+				config.StopLossMaxHoldingMinutes = 2
+			},
+			candles:  newTestPriceCandles(100, 101, 100, 99),
+			expected: [][]int64{{}, {}, {1}},
+			check:    exitPrice(99),
+		},
+	})
+}
+
+func TestStopLossAtrStopStaysAboveZero(t *testing.T) {
+	config := newTestConfig()
+	config.StopLossMode = StopLossAtrMode
+	config.StopLossAtrPeriod = 1
+	config.StopLossAtrMultiplier = 6
+
+	buffer := newTestBuffer(&config)
+	indicator := NewStopLossSellIndicator(&config, buffer, newTestDatabase(t, &config))
+	runTestSellIndicator(buffer, &indicator, newTestCandles([]testCandle{
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 100, Low: 40, Close: 100},
+	}))
+
+	assertFloat(t, "stop price", CalcBottomPrice(100, STOP_LOSS_MAX_PERCENTAGE), indicator.GetStopPrice(Buy{Id: 1, ExchangeRate: 100}))
+}
+
+func TestTakeProfitLadderSellIndicator(t *testing.T) {
+	if !IsTakeProfitLadderEnabled() {
+		t.Skip("TAKE_PROFIT_LADDER is empty")
+	}
+
+	step := func(step int) func(t *testing.T, buys []Buy) {
+		return func(t *testing.T, buys []Buy) {
+			assertFloat(t, "exit price", CalcUpperPrice(100, TAKE_PROFIT_LADDER[step].TargetPercentage), buys[0].ExitPrice)
+			assertFloat(t, "sell coins", CalcTakeProfitStepCoins(buys[0], step), buys[0].SellCoins)
+		}
+	}
+	stepPrice := func(step int, addition float64) float64 {
+		return CalcUpperPrice(100, TAKE_PROFIT_LADDER[step].TargetPercentage) + addition
+	}
+
+	runSellIndicatorTests(t, "TakeProfitLadder", []sellIndicatorTest{
+		{
+			name:     "first step",
+			candles:  newTestPriceCandles(100, stepPrice(0, -0.05), stepPrice(0, 0.05)),
+			expected: [][]int64{{}, {1}},
+			check:    step(0),
+		},
+		{
+			name:    "next step after the first one is sold",
+			candles: newTestPriceCandles(100, stepPrice(0, 0.05), stepPrice(1, 0.05)),
+			afterBuy: func(db *Database, buy Buy) {
+				db.AddSell(CANDLE_SYMBOL, CalcTakeProfitStepCoins(buy, 0), stepPrice(0, 0), 0, buy.Id, testStartTime)
+			},
+			expected: [][]int64{{}, {1}},
+			check:    step(1),
+		},
+	})
+}
+
+func TestProfitLockSellIndicator(t *testing.T) {
+	config := func(config *Config) {
+		config.ProfitLockActivationPercentage = 1
+		config.ProfitLockStepPercentage = 0.5
+	}
+	exitPrice := func(price float64) func(t *testing.T, buys []Buy) {
+		return func(t *testing.T, buys []Buy) {
+			if buys[0].BuyType != StopLoss {
+				t.Errorf("expected stop loss, got %d", buys[0].BuyType)
+			}
+			assertFloat(t, "exit price", price, buys[0].ExitPrice)
+		}
+	}
+
+	runSellIndicatorTests(t, "ProfitLock", []sellIndicatorTest{
+		{
+			name:   "break-even stop",
+			config: config,
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 100, Low: 100, Close: 100},
+				{Open: 100, High: 100.8, Low: 100, Close: 100.8},
+				{Open: 100.8, High: 101.2, Low: 100.8, Close: 101.2},
+				{Open: 101.2, High: 101.2, Low: 100.5, Close: 100.5},
+				{Open: 100.5, High: 100.5, Low: 100.1, Close: 100.1},
+			}),
+			expected: [][]int64{{}, {}, {}, {1}},
+			check:    exitPrice(CalcUpperPrice(100, COMMISSION*2)),
+		},
+		{
+			name:   "stepped stop",
+			config: config,
+			candles: newTestCandles([]testCandle{
+				{Open: 100, High: 100, Low: 100, Close: 100},
+				{Open: 100, High: 102, Low: 100, Close: 102},
+				{Open: 102, High: 102, Low: 101.5, Close: 101.5},
+				{Open: 101, High: 101, Low: 100, Close: 100},
+			}),
+			expected: [][]int64{{}, {}, {1}},
+			check:    exitPrice(101),
+		},
+		{
+			name:     "not activated",
+			config:   config,
+			candles:  newTestPriceCandles(100, 100.8, 99, 95),
+			expected: [][]int64{{}, {}, {}},
+		},
+	})
+}
+
+func TestDecayingTakeProfitSellIndicator(t *testing.T) {
+	// Flat prices after the buy, the target decays for an hour
+	flatPrices := func(price float64) []Candle {
+		prices := []float64{100}
+		for i := 0; i < 60; i++ {
+			prices = append(prices, price)
+		}
+
+		return newTestPriceCandles(prices...)
+	}
+
+	runSellIndicatorTests(t, "DecayingTakeProfit", []sellIndicatorTest{
+		{
+			name:     "linear",
+			config:   func(config *Config) { config.DecayTakeProfitMode = DecayLinearMode },
+			candles:  flatPrices(100.3),
+			expected: sellSignalsFrom(60, 47, 1),
+			check: func(t *testing.T, buys []Buy) {
+				assertFloat(t, "exit price", CalcUpperPrice(100, COMMISSION*2), buys[0].ExitPrice)
+			},
+		},
+		{
+			name: "exponential",
+			config: func(config *Config) {
+				config.DecayTakeProfitMode = DecayExponentialMode
+				config.DecayTakeProfitRate = 3
+			},
+			candles:  flatPrices(100.3),
+			expected: sellSignalsFrom(60, 28, 1),
+		},
+		{
+			name: "exponential with a zero rate is linear",
+			config: func(config *Config) {
+				config.DecayTakeProfitMode = DecayExponentialMode
+				config.DecayTakeProfitRate = 0
+			},
+			candles:  flatPrices(100.3),
+			expected: sellSignalsFrom(60, 47, 1),
+		},
+		{
+			name:     "floor below the break-even",
+			config:   func(config *Config) { config.DecayTakeProfitFloorPercentage = -1 },
+			candles:  flatPrices(99.5),
+			expected: sellSignalsFrom(60, 47, 1),
+		},
+	})
+}
+
+func TestSellIndicatorsAreTraceable(t *testing.T) {
+	random, _ := NewRand(1)
+
+	for name, constructor := range sellIndicatorConstructors {
+		config := InitBotConfig(random, GetDefaultSearchSpace())
+		buffer := newTestBuffer(&config)
+		indicator := constructor(&config, buffer, newTestDatabase(t, &config))
+
+		traceable, ok := indicator.(TraceableIndicator)
+		if !ok {
+			t.Errorf("%s has no trace values", name)
+			continue
+		}
+
+		runTestSellIndicator(buffer, indicator, newTestPriceCandles(100, 99, 98))
+		traceable.GetTraceValues()
+	}
+}
+
+func TestLeverageSellIndicatorTraceValues(t *testing.T) {
+	config := newTestConfig()
+	buffer := newTestBuffer(&config)
+	db := newTestDatabase(t, &config)
+	indicator := NewLeverageSellIndicator(&config, buffer, db)
+
+	runTestSellIndicator(buffer, &indicator, newTestPriceCandles(100))
+	addTestBuy(db, buffer, &indicator)
+
+	values := map[string]float64{}
+	for _, value := range indicator.GetTraceValues() {
+		values[value.Name] = value.Value
+	}
+
+	assertFloat(t, "upper price", CalcUpperPrice(100, config.HighSellPercentage), values["upper_price_1"])
+	assertFloat(t, "liquidation price", CalcBottomPrice(100, GetLeverageLiquidationPercentage(config.Leverage)), values["liquidation_price_1"])
+}
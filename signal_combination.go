@@ -0,0 +1,679 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Grammar of the buy and sell signal expressions:
+//
+//	expr    = and { "OR" and }
+//	and     = unary { "AND" unary }
+//	unary   = "NOT" unary | primary
+//	primary = "(" expr ")" | vote | score | Name
+//	vote    = "VOTE" "(" [ count "," ] expr { "," expr } ")"
+//	score   = "SCORE" "(" [ threshold "," ] [ weight "*" ] expr { "," [ weight "*" ] expr } ")"
+//
+// VOTE fires when at least count items fire, SCORE fires when the weights of the
+// fired items reach the threshold share of the total weight. Omitted count and
+// threshold are taken from the BuySignalVoteCount and BuySignalScoreThreshold genes.
+
+const (
+	BuySignalAndOperator = 0
+	BuySignalOrOperator  = 1
+)
+
+type signalKind int
+
+const (
+	signalIndicator signalKind = 0
+	signalAnd       signalKind = 1
+	signalOr        signalKind = 2
+	signalNot       signalKind = 3
+	signalVote      signalKind = 4
+	signalScore     signalKind = 5
+)
+
+type SignalExpression struct {
+	kind      signalKind
+	name      string
+	children  []*SignalExpression
+	weights   []float64
+	count     int
+	threshold float64
+}
+
+func ParseSignalExpression(expression string) *SignalExpression {
+	parser := signalParser{tokens: tokenizeSignalExpression(expression)}
+	result := parser.parseOr()
+
+	if token := parser.peek(); token != "" {
+		panic(fmt.Sprintf("Unexpected %q in signal expression: %s", token, expression))
+	}
+
+	return result
+}
+
+// The expression of the BuySignalExpression gene. The evolved one is
+// ((Term1 Operator2 Term2) Operator3 Term3) Operator4 Term4 AND LessThanPreviousBuy,
+// where a term is an indicator of BUY_SIGNAL_TERMS, negated by its Not gene, and the empty terms are skipped
+func GetBuySignalExpression(config *Config) string {
+	if config.BuySignalExpression != EVOLVED_BUY_SIGNAL_EXPRESSION {
+		return BUY_SIGNAL_EXPRESSIONS[config.BuySignalExpression]
+	}
+
+	terms := []int{config.BuySignalTerm1, config.BuySignalTerm2, config.BuySignalTerm3, config.BuySignalTerm4}
+	nots := []int{config.BuySignalNot1, config.BuySignalNot2, config.BuySignalNot3, config.BuySignalNot4}
+	operators := []int{BuySignalAndOperator, config.BuySignalOperator2, config.BuySignalOperator3, config.BuySignalOperator4}
+
+	expression := ""
+	termsCount := 0
+	for index, term := range terms {
+		name := BUY_SIGNAL_TERMS[term]
+		if name == "" {
+			continue
+		}
+
+		if nots[index] == 1 {
+			name = "NOT " + name
+		}
+
+		switch termsCount {
+		case 0:
+			expression = name
+		case 1:
+			expression = fmt.Sprintf("%s %s %s", expression, getBuySignalOperatorName(operators[index]), name)
+		default:
+			expression = fmt.Sprintf("(%s) %s %s", expression, getBuySignalOperatorName(operators[index]), name)
+		}
+		termsCount++
+	}
+
+	switch termsCount {
+	case 0:
+		panic("Evolved buy signal expression without terms")
+	case 1:
+		return expression + " AND LessThanPreviousBuy"
+	}
+
+	return fmt.Sprintf("(%s) AND LessThanPreviousBuy", expression)
+}
+
+func getBuySignalOperatorName(operator int) string {
+	if operator == BuySignalOrOperator {
+		return "OR"
+	}
+
+	return "AND"
+}
+
+// Indicator names in the order of the first appearance
+func (expression *SignalExpression) GetNames() []string {
+	var names []string
+	seen := map[string]bool{}
+
+	var collect func(node *SignalExpression)
+	collect = func(node *SignalExpression) {
+		if node.kind == signalIndicator && !seen[node.name] {
+			seen[node.name] = true
+			names = append(names, node.name)
+		}
+
+		for _, child := range node.children {
+			collect(child)
+		}
+	}
+	collect(expression)
+
+	return names
+}
+
+func (expression *SignalExpression) hasKind(kind signalKind) bool {
+	if expression.kind == kind {
+		return true
+	}
+
+	for _, child := range expression.children {
+		if child.hasKind(kind) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (expression *SignalExpression) resolveVoteCount(config *Config) int {
+	count := expression.count
+	if count == 0 {
+		count = config.BuySignalVoteCount
+	}
+
+	return MaxInt([]int{1, MinInt([]int{count, len(expression.children)})})
+}
+
+func (expression *SignalExpression) resolveScoreThreshold(config *Config) float64 {
+	threshold := expression.threshold
+	if threshold == 0 {
+		threshold = config.BuySignalScoreThreshold
+	}
+
+	return threshold * Sum(expression.weights)
+}
+
+func (expression *SignalExpression) evalBuy(config *Config, signals map[string]bool) bool {
+	switch expression.kind {
+	case signalAnd:
+		for _, child := range expression.children {
+			if !child.evalBuy(config, signals) {
+				return false
+			}
+		}
+		return true
+	case signalOr:
+		for _, child := range expression.children {
+			if child.evalBuy(config, signals) {
+				return true
+			}
+		}
+		return false
+	case signalNot:
+		return !expression.children[0].evalBuy(config, signals)
+	case signalVote:
+		votes := 0
+		for _, child := range expression.children {
+			if child.evalBuy(config, signals) {
+				votes++
+			}
+		}
+		return votes >= expression.resolveVoteCount(config)
+	case signalScore:
+		score := 0.0
+		for index, child := range expression.children {
+			if child.evalBuy(config, signals) {
+				score += expression.weights[index]
+			}
+		}
+		return score >= expression.resolveScoreThreshold(config)
+	}
+
+	return signals[expression.name]
+}
+
+func (expression *SignalExpression) evalSell(config *Config, signals map[string][]Buy) []Buy {
+	switch expression.kind {
+	case signalAnd:
+		buys := expression.children[0].evalSell(config, signals)
+		for _, child := range expression.children[1:] {
+			childBuys := child.evalSell(config, signals)
+			buys = mergeBuys(config, BuySliceIntersect(buys, childBuys), BuySliceIntersect(childBuys, buys))
+		}
+		return buys
+	case signalOr:
+		var buys []Buy
+		for _, child := range expression.children {
+			buys = mergeBuys(config, buys, child.evalSell(config, signals))
+		}
+		return buys
+	case signalVote, signalScore:
+		var buys []Buy
+		scores := map[int64]float64{}
+		for index, child := range expression.children {
+			weight := 1.0
+			if expression.kind == signalScore {
+				weight = expression.weights[index]
+			}
+
+			childBuys := child.evalSell(config, signals)
+			for _, buy := range childBuys {
+				scores[buy.Id] += weight
+			}
+			buys = mergeBuys(config, buys, childBuys)
+		}
+
+		threshold := float64(expression.resolveVoteCount(config))
+		if expression.kind == signalScore {
+			threshold = expression.resolveScoreThreshold(config)
+		}
+
+		var result []Buy
+		for _, buy := range buys {
+			if scores[buy.Id] >= threshold {
+				result = append(result, buy)
+			}
+		}
+		return result
+	}
+
+	return signals[expression.name]
+}
+
+// Union by id, a buy marked by some indicator (liquidation, time cancel, stop loss) keeps the mark
+func mergeBuys(config *Config, buys1, buys2 []Buy) []Buy {
+	result := append([]Buy{}, buys1...)
+
+	for _, buy2 := range buys2 {
+		found := false
+		for idx := range result {
+			if result[idx].Id == buy2.Id {
+				if result[idx].BuyType == 0 || isStoppedBefore(config, buy2, result[idx]) {
+					result[idx] = buy2
+				}
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			result = append(result, buy2)
+		}
+	}
+
+	return result
+}
+
+// On the way down the higher stop is filled first, a stop above the liquidation price is filled before it
+func isStoppedBefore(config *Config, buy, other Buy) bool {
+	if buy.BuyType != StopLoss {
+		return false
+	}
+
+	switch other.BuyType {
+	case StopLoss:
+		return buy.ExitPrice > other.ExitPrice
+	case Liquidation:
+		return buy.ExitPrice > CalcBottomPrice(buy.ExchangeRate, GetLeverageLiquidationPercentage(config.Leverage))
+	}
+
+	return false
+}
+
+// --------------------------------
+
+type signalParser struct {
+	tokens   []string
+	position int
+}
+
+func tokenizeSignalExpression(expression string) []string {
+	var tokens []string
+	runes := []rune(expression)
+
+	for idx := 0; idx < len(runes); {
+		char := runes[idx]
+
+		switch {
+		case unicode.IsSpace(char):
+			idx++
+		case strings.ContainsRune("(),*", char):
+			tokens = append(tokens, string(char))
+			idx++
+		case unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' || char == '.':
+			start := idx
+			for idx < len(runes) && (unicode.IsLetter(runes[idx]) || unicode.IsDigit(runes[idx]) || runes[idx] == '_' || runes[idx] == '.') {
+				idx++
+			}
+			tokens = append(tokens, string(runes[start:idx]))
+		default:
+			panic(fmt.Sprintf("Unexpected %q in signal expression: %s", char, expression))
+		}
+	}
+
+	return tokens
+}
+
+func (parser *signalParser) peek() string {
+	if parser.position >= len(parser.tokens) {
+		return ""
+	}
+
+	return parser.tokens[parser.position]
+}
+
+func (parser *signalParser) next() string {
+	token := parser.peek()
+	parser.position++
+
+	return token
+}
+
+func (parser *signalParser) expect(token string) {
+	if got := parser.next(); got != token {
+		panic(fmt.Sprintf("Expected %q in signal expression, got %q", token, got))
+	}
+}
+
+func (parser *signalParser) parseOr() *SignalExpression {
+	children := []*SignalExpression{parser.parseAnd()}
+	for parser.peek() == "OR" {
+		parser.next()
+		children = append(children, parser.parseAnd())
+	}
+
+	if 1 == len(children) {
+		return children[0]
+	}
+
+	return &SignalExpression{kind: signalOr, children: children}
+}
+
+func (parser *signalParser) parseAnd() *SignalExpression {
+	children := []*SignalExpression{parser.parseUnary()}
+	for parser.peek() == "AND" {
+		parser.next()
+		children = append(children, parser.parseUnary())
+	}
+
+	if 1 == len(children) {
+		return children[0]
+	}
+
+	return &SignalExpression{kind: signalAnd, children: children}
+}
+
+func (parser *signalParser) parseUnary() *SignalExpression {
+	if parser.peek() == "NOT" {
+		parser.next()
+		return &SignalExpression{kind: signalNot, children: []*SignalExpression{parser.parseUnary()}}
+	}
+
+	return parser.parsePrimary()
+}
+
+func (parser *signalParser) parsePrimary() *SignalExpression {
+	token := parser.next()
+
+	switch token {
+	case "(":
+		expression := parser.parseOr()
+		parser.expect(")")
+		return expression
+	case "VOTE", "SCORE":
+		return parser.parseVoteOrScore(token)
+	case "", ")", ",", "*", "AND", "OR", "NOT":
+		panic(fmt.Sprintf("Unexpected %q in signal expression", token))
+	}
+
+	if _, err := strconv.ParseFloat(token, 64); err == nil {
+		panic(fmt.Sprintf("Unexpected number %q in signal expression", token))
+	}
+
+	return &SignalExpression{kind: signalIndicator, name: token}
+}
+
+func (parser *signalParser) parseVoteOrScore(token string) *SignalExpression {
+	expression := &SignalExpression{kind: signalVote}
+	if token == "SCORE" {
+		expression.kind = signalScore
+	}
+
+	parser.expect("(")
+
+	// Leading count or threshold
+	if value, err := strconv.ParseFloat(parser.peek(), 64); err == nil && parser.lookAhead(1) == "," {
+		parser.next()
+		parser.next()
+		expression.count = int(value)
+		expression.threshold = value
+	}
+
+	for {
+		weight := 1.0
+		if value, err := strconv.ParseFloat(parser.peek(), 64); err == nil && parser.lookAhead(1) == "*" {
+			if expression.kind != signalScore {
+				panic("Weights are allowed only in SCORE")
+			}
+			parser.next()
+			parser.next()
+			weight = value
+		}
+
+		expression.children = append(expression.children, parser.parseOr())
+		expression.weights = append(expression.weights, weight)
+
+		if parser.peek() != "," {
+			break
+		}
+		parser.next()
+	}
+	parser.expect(")")
+
+	if expression.kind == signalVote {
+		expression.threshold = 0
+	} else {
+		expression.count = 0
+	}
+
+	return expression
+}
+
+func (parser *signalParser) lookAhead(offset int) string {
+	position := parser.position + offset
+	if position >= len(parser.tokens) {
+		return ""
+	}
+
+	return parser.tokens[position]
+}
+
+// --------------------------------
+
+type BuyIndicatorConstructor func(config *Config, buffer *Buffer, db *Database) BuyIndicator
+
+var buyIndicatorConstructors = map[string]BuyIndicatorConstructor{
+	"BackTrailing": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewBackTrailingBuyIndicator(config, buffer, db)
+		return &indicator
+	},
+	"BuysCount": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewBuysCountIndicator(config, buffer, db)
+		return &indicator
+	},
+	"WaitForPeriod": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewWaitForPeriodIndicator(config, buffer, db)
+		return &indicator
+	},
+	"BigFall": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewBigFallIndicator(config, buffer, db)
+		return &indicator
+	},
+	"GradientDescent": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewGradientDescentIndicator(config, buffer, db)
+		return &indicator
+	},
+	"LessThanPreviousBuy": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewLessThanPreviousBuyIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Rsi": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewRsiIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Macd": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewMacdIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Bollinger": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewBollingerIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Stochastic": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewStochasticIndicator(config, buffer, db)
+		return &indicator
+	},
+	"VolumeSpike": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewVolumeSpikeIndicator(config, buffer, db)
+		return &indicator
+	},
+	"TakerImbalance": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewTakerImbalanceIndicator(config, buffer, db)
+		return &indicator
+	},
+	"VwapDeviation": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewVwapDeviationIndicator(config, buffer, db)
+		return &indicator
+	},
+	"ObvTrend": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewObvTrendIndicator(config, buffer, db)
+		return &indicator
+	},
+	"HigherTrend": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewHigherTrendIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Regime": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewRegimeIndicator(config, buffer, db)
+		return &indicator
+	},
+	"CircuitBreaker": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewCircuitBreakerIndicator(config, buffer, db)
+		return &indicator
+	},
+	"TradingWindow": func(config *Config, buffer *Buffer, db *Database) BuyIndicator {
+		indicator := NewTradingWindowIndicator(config, buffer, db)
+		return &indicator
+	},
+}
+
+type SellIndicatorConstructor func(config *Config, buffer *Buffer, db *Database) SellIndicator
+
+var sellIndicatorConstructors = map[string]SellIndicatorConstructor{
+	"HighPercentage": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewHighPercentageSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"DesiredPrice": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewDesiredPriceSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Trailing": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewTrailingSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"Leverage": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewLeverageSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"StopLoss": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewStopLossSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"TakeProfitLadder": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewTakeProfitLadderSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"ProfitLock": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewProfitLockSellIndicator(config, buffer, db)
+		return &indicator
+	},
+	"DecayingTakeProfit": func(config *Config, buffer *Buffer, db *Database) SellIndicator {
+		indicator := NewDecayingTakeProfitSellIndicator(config, buffer, db)
+		return &indicator
+	},
+}
+
+// --------------------------------
+
+type BuySignal struct {
+	config     *Config
+	expression *SignalExpression
+	names      []string
+	indicators map[string]BuyIndicator
+	signals    map[string]bool
+}
+
+func NewBuySignal(expression string, config *Config, buffer *Buffer, db *Database) BuySignal {
+	signal := BuySignal{
+		config:     config,
+		expression: ParseSignalExpression(expression),
+		indicators: map[string]BuyIndicator{},
+	}
+
+	signal.names = signal.expression.GetNames()
+	for _, name := range signal.names {
+		constructor, ok := buyIndicatorConstructors[name]
+		if !ok {
+			panic(fmt.Sprintf("Unknown buy indicator %q in signal expression: %s", name, expression))
+		}
+		signal.indicators[name] = constructor(config, buffer, db)
+	}
+
+	return signal
+}
+
+func (signal *BuySignal) GetIndicators() []BuyIndicator {
+	var indicators []BuyIndicator
+	for _, name := range signal.names {
+		indicators = append(indicators, signal.indicators[name])
+	}
+
+	return indicators
+}
+
+// Every indicator is asked once per candle, so stateful ones stay consistent
+func (signal *BuySignal) HasSignal() bool {
+	signals := map[string]bool{}
+	for _, name := range signal.names {
+		signals[name] = signal.indicators[name].HasSignal()
+	}
+	signal.signals = signals
+
+	return signal.expression.evalBuy(signal.config, signals)
+}
+
+// --------------------------------
+
+type SellSignal struct {
+	config     *Config
+	expression *SignalExpression
+	names      []string
+	indicators map[string]SellIndicator
+	signals    map[string][]Buy
+}
+
+func NewSellSignal(expression string, config *Config, buffer *Buffer, db *Database) SellSignal {
+	signal := SellSignal{
+		config:     config,
+		expression: ParseSignalExpression(expression),
+		indicators: map[string]SellIndicator{},
+	}
+
+	if signal.expression.hasKind(signalNot) {
+		panic(fmt.Sprintf("NOT is not supported in sell signal expression: %s", expression))
+	}
+
+	signal.names = signal.expression.GetNames()
+	for _, name := range signal.names {
+		constructor, ok := sellIndicatorConstructors[name]
+		if !ok {
+			panic(fmt.Sprintf("Unknown sell indicator %q in signal expression: %s", name, expression))
+		}
+		signal.indicators[name] = constructor(config, buffer, db)
+	}
+
+	return signal
+}
+
+func (signal *SellSignal) GetIndicators() []SellIndicator {
+	var indicators []SellIndicator
+	for _, name := range signal.names {
+		indicators = append(indicators, signal.indicators[name])
+	}
+
+	return indicators
+}
+
+func (signal *SellSignal) HasSignal() (bool, []Buy) {
+	signals := map[string][]Buy{}
+	for _, name := range signal.names {
+		if hasSignal, buys := signal.indicators[name].HasSignal(); hasSignal {
+			signals[name] = buys
+		}
+	}
+	signal.signals = signals
+
+	buys := signal.expression.evalSell(signal.config, signals)
+
+	return len(buys) > 0, buys
+}
@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+func TestGetBuySignalExpression(t *testing.T) {
+	term := func(name string) int {
+		for index, termName := range BUY_SIGNAL_TERMS {
+			if termName == name {
+				return index
+			}
+		}
+
+		t.Fatalf("unknown term %s", name)
+		return 0
+	}
+
+	tests := []struct {
+		name     string
+		config   func(config *Config)
+		expected string
+	}{
+		{
+			name:     "hand written expression",
+			config:   func(config *Config) { config.BuySignalExpression = 1 },
+			expected: BUY_SIGNAL_EXPRESSIONS[1],
+		},
+		{
+			name: "one term",
+			config: func(config *Config) {
+				config.BuySignalTerm1 = term("BigFall")
+			},
+			expected: "BigFall AND LessThanPreviousBuy",
+		},
+		{
+			name: "or group",
+			config: func(config *Config) {
+				config.BuySignalTerm1 = term("BigFall")
+				config.BuySignalOperator2 = BuySignalOrOperator
+				config.BuySignalTerm2 = term("Rsi")
+			},
+			expected: "(BigFall OR Rsi) AND LessThanPreviousBuy",
+		},
+		{
+			name: "nested terms with negation and a skipped term",
+			config: func(config *Config) {
+				config.BuySignalTerm1 = term("BigFall")
+				config.BuySignalOperator2 = BuySignalOrOperator
+				config.BuySignalTerm2 = term("Bollinger")
+				config.BuySignalOperator3 = BuySignalOrOperator
+				config.BuySignalOperator4 = BuySignalAndOperator
+				config.BuySignalTerm4 = term("CircuitBreaker")
+				config.BuySignalNot4 = 1
+			},
+			expected: "((BigFall OR Bollinger) AND NOT CircuitBreaker) AND LessThanPreviousBuy",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.BuySignalExpression = EVOLVED_BUY_SIGNAL_EXPRESSION
+			test.config(&config)
+
+			if expression := GetBuySignalExpression(&config); expression != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, expression)
+			}
+		})
+	}
+}
+
+func TestBuySignalTermsAreBuyIndicators(t *testing.T) {
+	for _, name := range BUY_SIGNAL_TERMS[1:] {
+		if _, ok := buyIndicatorConstructors[name]; !ok {
+			t.Errorf("unknown buy indicator %s", name)
+		}
+	}
+}
+
+func TestEvolvedBuySignalExpressionsParse(t *testing.T) {
+	space := GetDefaultSearchSpace()
+	random, _ := NewRand(7)
+
+	for i := 0; i < 200; i++ {
+		config := InitBotConfig(random, space)
+		config.BuySignalExpression = EVOLVED_BUY_SIGNAL_EXPRESSION
+
+		expression := ParseSignalExpression(GetBuySignalExpression(&config))
+		for _, name := range expression.GetNames() {
+			if _, ok := buyIndicatorConstructors[name]; !ok {
+				t.Fatalf("unknown buy indicator %s", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Incremental indicators, each candle costs O(1) instead of rerunning talib over the whole buffer.
+// Seeding and smoothing follow talib, so the values match talib run over the same candles.
+type StreamingIndicator interface {
+	Add(candle Candle)
+}
+
+type CandleValue func(candle Candle) float64
+
+func CandleClosePrice(candle Candle) float64 {
+	return candle.ClosePrice
+}
+
+func CandleHighPrice(candle Candle) float64 {
+	return candle.HighPrice
+}
+
+func CandleLowPrice(candle Candle) float64 {
+	return candle.LowPrice
+}
+
+// A period below 1 has no values to average or compare, a search space file must not reach it
+func checkStreamingPeriod(name string, period int) {
+	if period < 1 {
+		panic(fmt.Sprintf("Streaming %s period must be positive, got %d", name, period))
+	}
+}
+
+// --------------------------------
+
+// Last values of an indicator, oldest first
+type StreamingHistory struct {
+	values  []float64
+	maxSize int
+}
+
+func NewStreamingHistory(maxSize int) StreamingHistory {
+	return StreamingHistory{maxSize: MaxInt([]int{maxSize, 1})}
+}
+
+func (history *StreamingHistory) add(value float64) {
+	history.values = append(history.values, value)
+	if len(history.values) > history.maxSize {
+		history.values = history.values[1:]
+	}
+}
+
+func (history *StreamingHistory) GetValues() []float64 {
+	return history.values
+}
+
+func (history *StreamingHistory) IsReady() bool {
+	return len(history.values) > 0
+}
+
+func (history *StreamingHistory) Last() float64 {
+	if !history.IsReady() {
+		return 0
+	}
+
+	return history.values[len(history.values)-1]
+}
+
+// --------------------------------
+
+type StreamingSma struct {
+	StreamingHistory
+	period       int
+	window       []float64
+	total        float64
+	addsToResync int
+}
+
+func NewStreamingSma(period, historySize int) StreamingSma {
+	checkStreamingPeriod("SMA", period)
+
+	return StreamingSma{
+		StreamingHistory: NewStreamingHistory(historySize),
+		period:           period,
+	}
+}
+
+func (sma *StreamingSma) Add(candle Candle) {
+	sma.AddValue(candle.ClosePrice)
+}
+
+func (sma *StreamingSma) AddValue(value float64) {
+	sma.window = append(sma.window, value)
+	sma.total += value
+
+	if len(sma.window) > sma.period {
+		sma.total -= sma.window[0]
+		sma.window = sma.window[1:]
+	}
+
+	// The running total is summed again once per period, so the float error does not pile up
+	sma.addsToResync++
+	if sma.addsToResync >= sma.period {
+		sma.addsToResync = 0
+		sma.total = Sum(sma.window)
+	}
+
+	if len(sma.window) == sma.period {
+		sma.add(sma.total / float64(sma.period))
+	}
+}
+
+// --------------------------------
+
+type StreamingEma struct {
+	StreamingHistory
+	period int
+	k      float64
+	count  int
+	total  float64
+	ema    float64
+}
+
+func NewStreamingEma(period, historySize int) StreamingEma {
+	checkStreamingPeriod("EMA", period)
+
+	return StreamingEma{
+		StreamingHistory: NewStreamingHistory(historySize),
+		period:           period,
+		k:                2.0 / float64(period+1),
+	}
+}
+
+func (ema *StreamingEma) Add(candle Candle) {
+	ema.AddValue(candle.ClosePrice)
+}
+
+// Seeded with the SMA of the first period values like talib
+func (ema *StreamingEma) AddValue(value float64) {
+	ema.count++
+
+	if ema.count < ema.period {
+		ema.total += value
+		return
+	}
+
+	if ema.count == ema.period {
+		ema.total += value
+		ema.ema = ema.total / float64(ema.period)
+	} else {
+		ema.ema = ((value - ema.ema) * ema.k) + ema.ema
+	}
+
+	ema.add(ema.ema)
+}
+
+// --------------------------------
+
+type StreamingAtr struct {
+	StreamingHistory
+	period    int
+	count     int
+	prevClose float64
+	total     float64
+	atr       float64
+}
+
+func NewStreamingAtr(period, historySize int) StreamingAtr {
+	checkStreamingPeriod("ATR", period)
+
+	return StreamingAtr{
+		StreamingHistory: NewStreamingHistory(historySize),
+		period:           period,
+	}
+}
+
+// Wilder smoothing of the true range, seeded with the SMA of the first period true ranges
+func (atr *StreamingAtr) Add(candle Candle) {
+	atr.count++
+	prevClose := atr.prevClose
+	atr.prevClose = candle.ClosePrice
+
+	if atr.count == 1 {
+		return
+	}
+
+	trueRange := math.Max(
+		candle.HighPrice-candle.LowPrice,
+		math.Max(math.Abs(candle.HighPrice-prevClose), math.Abs(candle.LowPrice-prevClose)),
+	)
+	periodF := float64(atr.period)
+
+	if atr.count <= atr.period {
+		atr.total += trueRange
+		return
+	}
+
+	if atr.count == atr.period+1 {
+		atr.total += trueRange
+		atr.atr = atr.total / periodF
+	} else {
+		atr.atr = ((atr.atr * (periodF - 1.0)) + trueRange) / periodF
+	}
+
+	atr.add(atr.atr)
+}
+
+// --------------------------------
+
+type StreamingRsi struct {
+	StreamingHistory
+	period    int
+	count     int
+	prevValue float64
+	prevGain  float64
+	prevLoss  float64
+}
+
+func NewStreamingRsi(period, historySize int) StreamingRsi {
+	checkStreamingPeriod("RSI", period)
+
+	return StreamingRsi{
+		StreamingHistory: NewStreamingHistory(historySize),
+		period:           period,
+	}
+}
+
+func (rsi *StreamingRsi) Add(candle Candle) {
+	rsi.AddValue(candle.ClosePrice)
+}
+
+func (rsi *StreamingRsi) AddValue(value float64) {
+	rsi.count++
+	diff := value - rsi.prevValue
+	rsi.prevValue = value
+
+	if rsi.count == 1 {
+		return
+	}
+
+	periodF := float64(rsi.period)
+
+	if rsi.count <= rsi.period+1 {
+		if diff < 0 {
+			rsi.prevLoss -= diff
+		} else {
+			rsi.prevGain += diff
+		}
+
+		if rsi.count < rsi.period+1 {
+			return
+		}
+
+		rsi.prevLoss /= periodF
+		rsi.prevGain /= periodF
+	} else {
+		rsi.prevLoss *= periodF - 1
+		rsi.prevGain *= periodF - 1
+		if diff < 0 {
+			rsi.prevLoss -= diff
+		} else {
+			rsi.prevGain += diff
+		}
+		rsi.prevLoss /= periodF
+		rsi.prevGain /= periodF
+	}
+
+	total := rsi.prevGain + rsi.prevLoss
+	if -0.00000000000001 < total && total < 0.00000000000001 {
+		rsi.add(0)
+		return
+	}
+
+	rsi.add(100.0 * (rsi.prevGain / total))
+}
+
+// --------------------------------
+
+// Rolling extreme over the last period candles, a monotonic deque keeps it O(1) amortized
+type StreamingRollingExtreme struct {
+	period  int
+	value   CandleValue
+	isMax   bool
+	count   int
+	indexes []int
+	values  []float64
+}
+
+func NewStreamingRollingMax(period int, value CandleValue) StreamingRollingExtreme {
+	checkStreamingPeriod("rolling max", period)
+
+	return StreamingRollingExtreme{period: period, value: value, isMax: true}
+}
+
+func NewStreamingRollingMin(period int, value CandleValue) StreamingRollingExtreme {
+	checkStreamingPeriod("rolling min", period)
+
+	return StreamingRollingExtreme{period: period, value: value, isMax: false}
+}
+
+func (extreme *StreamingRollingExtreme) Add(candle Candle) {
+	value := extreme.value(candle)
+	index := extreme.count
+	extreme.count++
+
+	for len(extreme.values) > 0 {
+		last := extreme.values[len(extreme.values)-1]
+		if (extreme.isMax && last > value) || (!extreme.isMax && last < value) {
+			break
+		}
+
+		extreme.values = extreme.values[:len(extreme.values)-1]
+		extreme.indexes = extreme.indexes[:len(extreme.indexes)-1]
+	}
+
+	extreme.values = append(extreme.values, value)
+	extreme.indexes = append(extreme.indexes, index)
+
+	for extreme.indexes[0] <= index-extreme.period {
+		extreme.values = extreme.values[1:]
+		extreme.indexes = extreme.indexes[1:]
+	}
+}
+
+func (extreme *StreamingRollingExtreme) IsReady() bool {
+	return extreme.count >= extreme.period
+}
+
+func (extreme *StreamingRollingExtreme) Last() float64 {
+	if 0 == len(extreme.values) {
+		return 0
+	}
+
+	return extreme.values[0]
+}
+
+// --------------------------------
+
+// Streaming indicators are shared by name, so two indicators with the same period update one instance
+func (buffer *Buffer) registerStreaming(key string, create func() StreamingIndicator) StreamingIndicator {
+	if buffer.streamings == nil {
+		buffer.streamings = map[string]StreamingIndicator{}
+	}
+
+	if indicator, ok := buffer.streamings[key]; ok {
+		return indicator
+	}
+
+	indicator := create()
+	for _, candle := range buffer.candles {
+		indicator.Add(candle)
+	}
+
+	buffer.streamings[key] = indicator
+	buffer.streamingsOrder = append(buffer.streamingsOrder, indicator)
+
+	return indicator
+}
+
+func (buffer *Buffer) GetSma(period, historySize int) *StreamingSma {
+	key := fmt.Sprintf("sma/%d/%d", period, historySize)
+
+	return buffer.registerStreaming(key, func() StreamingIndicator {
+		sma := NewStreamingSma(period, historySize)
+		return &sma
+	}).(*StreamingSma)
+}
+
+func (buffer *Buffer) GetEma(period, historySize int) *StreamingEma {
+	key := fmt.Sprintf("ema/%d/%d", period, historySize)
+
+	return buffer.registerStreaming(key, func() StreamingIndicator {
+		ema := NewStreamingEma(period, historySize)
+		return &ema
+	}).(*StreamingEma)
+}
+
+func (buffer *Buffer) GetAtr(period int) *StreamingAtr {
+	key := fmt.Sprintf("atr/%d", period)
+
+	return buffer.registerStreaming(key, func() StreamingIndicator {
+		atr := NewStreamingAtr(period, 1)
+		return &atr
+	}).(*StreamingAtr)
+}
+
+func (buffer *Buffer) GetRsi(period int) *StreamingRsi {
+	key := fmt.Sprintf("rsi/%d", period)
+
+	return buffer.registerStreaming(key, func() StreamingIndicator {
+		rsi := NewStreamingRsi(period, 1)
+		return &rsi
+	}).(*StreamingRsi)
+}
+
+func (buffer *Buffer) GetRollingMax(name string, period int, value CandleValue) *StreamingRollingExtreme {
+	key := fmt.Sprintf("max/%s/%d", name, period)
+
+	return buffer.registerStreaming(key, func() StreamingIndicator {
+		extreme := NewStreamingRollingMax(period, value)
+		return &extreme
+	}).(*StreamingRollingExtreme)
+}
+
+func (buffer *Buffer) GetRollingMin(name string, period int, value CandleValue) *StreamingRollingExtreme {
+	key := fmt.Sprintf("min/%s/%d", name, period)
+
+	return buffer.registerStreaming(key, func() StreamingIndicator {
+		extreme := NewStreamingRollingMin(period, value)
+		return &extreme
+	}).(*StreamingRollingExtreme)
+}
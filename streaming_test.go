@@ -0,0 +1,109 @@
+package main
+
+import (
+	"github.com/markcheno/go-talib"
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestSyntheticCandles(count int) []Candle {
+	source := NewSyntheticCandleSource(SyntheticConfig{
+		Seed:           1,
+		StartTime:      time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Interval:       time.Minute,
+		CandlesCount:   count,
+		StartPrice:     4000,
+		StepsPerCandle: 30,
+		BaseVolume:     1000,
+		Volatility:     0.006,
+	}, CANDLE_SYMBOL)
+
+	return CollectCandles(&source)
+}
+
+// The streaming values are the tail of talib run over the whole history, after the warm-up
+func assertTalibValues(t *testing.T, name string, expected []float64, warmUp int, actual []float64) {
+	t.Helper()
+
+	expected = expected[warmUp:]
+	if len(expected) != len(actual) {
+		t.Fatalf("%s: expected %d values, got %d", name, len(expected), len(actual))
+	}
+
+	for index := range expected {
+		if math.Abs(expected[index]-actual[index]) > 1e-6 {
+			t.Fatalf("%s: value %d: expected %f, got %f", name, index, expected[index], actual[index])
+		}
+	}
+}
+
+func TestStreamingIndicatorsMatchTalib(t *testing.T) {
+	candles := newTestSyntheticCandles(2000)
+	closePrices := GetClosePrices(candles)
+	highPrices := GetHighPrices(candles)
+	lowPrices := GetLowPrices(candles)
+
+	for _, period := range []int{1, 2, 14, 50} {
+		sma := NewStreamingSma(period, len(candles))
+		ema := NewStreamingEma(period, len(candles))
+		atr := NewStreamingAtr(period, len(candles))
+		rsi := NewStreamingRsi(period, len(candles))
+		for _, candle := range candles {
+			sma.Add(candle)
+			ema.Add(candle)
+			atr.Add(candle)
+			rsi.Add(candle)
+		}
+
+		assertTalibValues(t, "SMA", talib.Sma(closePrices, period), period-1, sma.GetValues())
+		assertTalibValues(t, "EMA", talib.Ema(closePrices, period), period-1, ema.GetValues())
+		assertTalibValues(t, "ATR", talib.Atr(highPrices, lowPrices, closePrices, period), period, atr.GetValues())
+
+		// talib has no period 1 RSI
+		if period > 1 {
+			assertTalibValues(t, "RSI", talib.Rsi(closePrices, period), period, rsi.GetValues())
+		}
+	}
+}
+
+func TestStreamingRollingExtremeMatchesWindow(t *testing.T) {
+	candles := newTestSyntheticCandles(500)
+
+	for _, period := range []int{1, 3, 20} {
+		rollingMax := NewStreamingRollingMax(period, CandleHighPrice)
+		rollingMin := NewStreamingRollingMin(period, CandleLowPrice)
+
+		for index, candle := range candles {
+			rollingMax.Add(candle)
+			rollingMin.Add(candle)
+
+			window := candles[MaxInt([]int{0, index - period + 1}) : index+1]
+			assertFloat(t, "rolling max", Max(GetHighPrices(window)), rollingMax.Last())
+			assertFloat(t, "rolling min", Min(GetLowPrices(window)), rollingMin.Last())
+		}
+	}
+}
+
+func TestStreamingIndicatorsRejectNonPositivePeriod(t *testing.T) {
+	constructors := map[string]func(){
+		"SMA":         func() { NewStreamingSma(0, 1) },
+		"EMA":         func() { NewStreamingEma(0, 1) },
+		"ATR":         func() { NewStreamingAtr(-1, 1) },
+		"RSI":         func() { NewStreamingRsi(0, 1) },
+		"rolling max": func() { NewStreamingRollingMax(0, CandleHighPrice) },
+		"rolling min": func() { NewStreamingRollingMin(0, CandleLowPrice) },
+	}
+
+	for name, constructor := range constructors {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected a panic")
+				}
+			}()
+
+			constructor()
+		})
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"time"
+)
+
+type SyntheticRegime int
+
+const (
+	SyntheticSideways SyntheticRegime = 0
+	SyntheticBull     SyntheticRegime = 1
+	SyntheticBear     SyntheticRegime = 2
+)
+
+type SyntheticRegimeParams struct {
+	Drift      float64
+	Volatility float64
+}
+
+type SyntheticConfig struct {
+	Seed           int64
+	StartTime      time.Time
+	Interval       time.Duration
+	CandlesCount   int
+	StartPrice     float64
+	StepsPerCandle int
+	BaseVolume     float64
+
+	// Geometric Brownian motion, log return per candle
+	Drift      float64
+	Volatility float64
+
+	// Regime switching, drift is added and volatility is multiplied
+	Regimes                 map[SyntheticRegime]SyntheticRegimeParams
+	RegimeSwitchProbability float64
+
+	// Flash crashes, percentages of the price
+	JumpProbability        float64
+	JumpMinPercentage      float64
+	JumpMaxPercentage      float64
+	JumpRecoveryPercentage float64
+
+	// GARCH(1,1) variance of the candle log return, disabled when alpha and beta are zero.
+	// Crashes are not fed back, so a single jump does not blow the variance up.
+	GarchOmega float64
+	GarchAlpha float64
+	GarchBeta  float64
+}
+
+type SyntheticGenerator struct {
+	config   SyntheticConfig
+	random   *rand.Rand
+	index    int
+	price    float64
+	regime   SyntheticRegime
+	variance float64
+	lastRet  float64
+}
+
+func NewSyntheticGenerator(config SyntheticConfig) SyntheticGenerator {
+	return SyntheticGenerator{
+		config:   config,
+		random:   rand.New(rand.NewSource(config.Seed)),
+		price:    config.StartPrice,
+		regime:   SyntheticSideways,
+		variance: config.Volatility * config.Volatility,
+	}
+}
+
+func (generator *SyntheticGenerator) HasNext() bool {
+	return generator.index < generator.config.CandlesCount
+}
+
+func (generator *SyntheticGenerator) NextRecord() candleRecord {
+	config := generator.config
+	generator.switchRegime()
+
+	regime := generator.getRegimeParams()
+	volatility := math.Sqrt(generator.nextVariance()) * regime.Volatility
+	drift := config.Drift + regime.Drift
+
+	steps := MaxInt([]int{config.StepsPerCandle, 1})
+	stepDrift := drift / float64(steps)
+	stepVolatility := volatility / math.Sqrt(float64(steps))
+
+	jumpStep, jumpPercentage := generator.nextJump(steps)
+	recoveryPerStep := 0.0
+
+	openPrice := generator.price
+	highPrice := openPrice
+	lowPrice := openPrice
+	price := openPrice
+	diffusionRet := 0.0
+
+	for step := 0; step < steps; step++ {
+		stepRet := stepDrift + stepVolatility*generator.random.NormFloat64()
+		diffusionRet += stepRet
+		price *= math.Exp(stepRet)
+
+		if step == jumpStep {
+			fallPrice := CalcValuePercentage(price, jumpPercentage)
+			price -= fallPrice
+
+			if remaining := steps - step - 1; remaining > 0 {
+				recoveryPerStep = CalcValuePercentage(fallPrice, config.JumpRecoveryPercentage) / float64(remaining)
+			}
+		} else if step > jumpStep && jumpStep >= 0 {
+			price += recoveryPerStep
+		}
+
+		highPrice = Max([]float64{highPrice, price})
+		lowPrice = Min([]float64{lowPrice, price})
+	}
+
+	generator.lastRet = diffusionRet
+	generator.price = price
+
+	openTime := config.StartTime.Add(config.Interval * time.Duration(generator.index))
+	closeTime := openTime.Add(config.Interval - time.Millisecond)
+	generator.index++
+
+	volume, takerBuyVolume := generator.nextVolumes(volatility, jumpPercentage)
+
+	return candleRecord{
+		OpenTime:                 openTime.UnixMilli(),
+		CloseTime:                closeTime.UnixMilli(),
+		OpenPrice:                openPrice,
+		HighPrice:                highPrice,
+		LowPrice:                 lowPrice,
+		ClosePrice:               price,
+		Volume:                   volume,
+		QuoteAssetVolume:         volume * (openPrice + price) / 2,
+		NumberOfTrades:           int64(volume * 10),
+		TakerBuyBaseAssetVolume:  takerBuyVolume,
+		TakerBuyQuoteAssetVolume: takerBuyVolume * (openPrice + price) / 2,
+	}
+}
+
+func (generator *SyntheticGenerator) switchRegime() {
+	config := generator.config
+	if 0 == len(config.Regimes) || generator.random.Float64() >= config.RegimeSwitchProbability {
+		return
+	}
+
+	regimes := []SyntheticRegime{SyntheticSideways, SyntheticBull, SyntheticBear}
+	for {
+		regime := regimes[generator.random.Intn(len(regimes))]
+		if _, ok := config.Regimes[regime]; ok && regime != generator.regime {
+			generator.regime = regime
+			return
+		}
+
+		if 1 == len(config.Regimes) {
+			return
+		}
+	}
+}
+
+func (generator *SyntheticGenerator) getRegimeParams() SyntheticRegimeParams {
+	if params, ok := generator.config.Regimes[generator.regime]; ok {
+		return params
+	}
+
+	return SyntheticRegimeParams{Drift: 0, Volatility: 1}
+}
+
+func (generator *SyntheticGenerator) nextVariance() float64 {
+	config := generator.config
+	if config.GarchAlpha == 0 && config.GarchBeta == 0 {
+		return config.Volatility * config.Volatility
+	}
+
+	generator.variance = config.GarchOmega +
+		config.GarchAlpha*generator.lastRet*generator.lastRet +
+		config.GarchBeta*generator.variance
+
+	maxVariance := SYNTHETIC_MAX_VOLATILITY_MULTIPLIER * SYNTHETIC_MAX_VOLATILITY_MULTIPLIER *
+		config.Volatility * config.Volatility
+	if generator.variance > maxVariance {
+		generator.variance = maxVariance
+	}
+
+	return generator.variance
+}
+
+func (generator *SyntheticGenerator) nextJump(steps int) (int, float64) {
+	config := generator.config
+	if generator.random.Float64() >= config.JumpProbability {
+		return -1, 0
+	}
+
+	step := generator.random.Intn(steps)
+	percentage := config.JumpMinPercentage +
+		generator.random.Float64()*(config.JumpMaxPercentage-config.JumpMinPercentage)
+
+	return step, percentage
+}
+
+func (generator *SyntheticGenerator) nextVolumes(volatility, jumpPercentage float64) (float64, float64) {
+	config := generator.config
+	volatilityFactor := 1.0
+	if config.Volatility > 0 {
+		volatilityFactor = volatility / config.Volatility
+	}
+
+	volume := config.BaseVolume *
+		volatilityFactor *
+		math.Exp(0.3*generator.random.NormFloat64()) *
+		(1 + jumpPercentage/2)
+
+	// Taker buyers dominate growing candles and sellers dominate falling ones
+	buyShare := 0.5
+	if volatility > 0 {
+		buyShare += math.Max(-0.4, math.Min(0.4, generator.lastRet/(4*volatility)))
+	}
+
+	return volume, volume * buyShare
+}
+
+// --------------------------------
+
+type SyntheticCandleSource struct {
+	symbol    string
+	generator SyntheticGenerator
+}
+
+func NewSyntheticCandleSource(config SyntheticConfig, symbol string) SyntheticCandleSource {
+	return SyntheticCandleSource{
+		symbol:    symbol,
+		generator: NewSyntheticGenerator(config),
+	}
+}
+
+func (source *SyntheticCandleSource) Next() (Candle, bool) {
+	if !source.generator.HasNext() {
+		return Candle{}, false
+	}
+
+	return source.generator.NextRecord().ToCandle(source.symbol), true
+}
+
+func (source *SyntheticCandleSource) Close() {
+}
+
+// --------------------------------
+
+func WriteSyntheticDataset(fileName string, config SyntheticConfig) {
+	file, err := os.Create(fileName)
+	if err != nil {
+		panic(err)
+	}
+	defer file.Close()
+
+	bufferedWriter := bufio.NewWriter(file)
+	writer := csv.NewWriter(bufferedWriter)
+	generator := NewSyntheticGenerator(config)
+
+	for generator.HasNext() {
+		if err := writer.Write(generator.NextRecord().ToCsvRecord()); err != nil {
+			panic(err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		panic(err)
+	}
+	if err := bufferedWriter.Flush(); err != nil {
+		panic(err)
+	}
+}
+
+func RunSyntheticDatasetsGenerator() {
+	if err := os.MkdirAll(DATASETS_DIRECTORY, 0755); err != nil {
+		panic(err)
+	}
+
+	for name, config := range GetSyntheticDatasetConfigs() {
+		fileName := GetDatasetFileName("synthetic-" + name)
+		WriteSyntheticDataset(fileName, config)
+
+		LogAndPrint(fmt.Sprintf("Synthetic dataset: %s", fileName))
+	}
+}
+
+func GetSyntheticDatasetConfigs() map[string]SyntheticConfig {
+	base := SyntheticConfig{
+		Seed:           SYNTHETIC_SEED,
+		StartTime:      time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC),
+		Interval:       GetCandleIntervalDuration(CANDLE_INTERVAL),
+		CandlesCount:   SYNTHETIC_CANDLES_COUNT,
+		StartPrice:     4000,
+		StepsPerCandle: 30,
+		BaseVolume:     1000,
+
+		Drift:      0,
+		Volatility: 0.006,
+	}
+
+	gbm := base
+
+	regimes := base
+	regimes.Regimes = map[SyntheticRegime]SyntheticRegimeParams{
+		SyntheticSideways: {Drift: 0, Volatility: 0.7},
+		SyntheticBull:     {Drift: 0.0008, Volatility: 1},
+		SyntheticBear:     {Drift: -0.001, Volatility: 1.4},
+	}
+	regimes.RegimeSwitchProbability = 0.005
+
+	crash := regimes
+	crash.JumpProbability = 0.0005
+	crash.JumpMinPercentage = 10
+	crash.JumpMaxPercentage = 40
+	crash.JumpRecoveryPercentage = 30
+
+	garch := crash
+	garch.GarchOmega = 0.000002
+	garch.GarchAlpha = 0.1
+	garch.GarchBeta = 0.85
+
+	return map[string]SyntheticConfig{
+		"gbm":     gbm,
+		"regimes": regimes,
+		"crash":   crash,
+		"garch":   garch,
+	}
+}
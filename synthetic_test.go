@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func readTestSyntheticDataset(t *testing.T, fileName string, config SyntheticConfig) []byte {
+	fileName = filepath.Join(t.TempDir(), fileName)
+	WriteSyntheticDataset(fileName, config)
+
+	content, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return content
+}
+
+func TestSyntheticDatasetIsReproducible(t *testing.T) {
+	for name, config := range GetSyntheticDatasetConfigs() {
+		t.Run(name, func(t *testing.T) {
+			config.CandlesCount = 500
+
+			first := readTestSyntheticDataset(t, "first.csv", config)
+			second := readTestSyntheticDataset(t, "second.csv", config)
+			if !bytes.Equal(first, second) {
+				t.Errorf("expected the same CSV for the same seed")
+			}
+
+			config.Seed++
+			if other := readTestSyntheticDataset(t, "other.csv", config); bytes.Equal(first, other) {
+				t.Errorf("expected another CSV for another seed")
+			}
+		})
+	}
+}
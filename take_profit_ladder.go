@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// One tranche of the partial take profit, see TAKE_PROFIT_LADDER
+type TakeProfitStep struct {
+	Percentage       float64
+	TargetPercentage float64
+}
+
+func IsTakeProfitLadderEnabled() bool {
+	return len(TAKE_PROFIT_LADDER) > 0
+}
+
+// Index of the first step that is not sold yet, steps are sold in order
+func GetNextTakeProfitStep(buy Buy) (int, bool) {
+	soldShare := buy.SoldCoins / buy.Coins
+	share := 0.0
+
+	for index := range TAKE_PROFIT_LADDER {
+		share += getTakeProfitStepShare(index)
+		if share > soldShare+0.000001 {
+			return index, true
+		}
+	}
+
+	return 0, false
+}
+
+func CalcTakeProfitStepPrice(buy Buy, step int) float64 {
+	return CalcUpperPrice(buy.ExchangeRate, TAKE_PROFIT_LADDER[step].TargetPercentage)
+}
+
+func CalcTakeProfitStepCoins(buy Buy, step int) float64 {
+	if step == len(TAKE_PROFIT_LADDER)-1 {
+		return buy.GetUnsoldCoins()
+	}
+
+	return buy.Coins * getTakeProfitStepShare(step)
+}
+
+// The percentages are normalized, so the steps always sell the whole buy
+func getTakeProfitStepShare(step int) float64 {
+	total := 0.0
+	for _, takeProfitStep := range TAKE_PROFIT_LADDER {
+		total += takeProfitStep.Percentage
+	}
+
+	return TAKE_PROFIT_LADDER[step].Percentage / total
+}
+
+// Quantities of the steps rounded down to the lot size, the last step gets the rest
+func SplitQuantityToLotSize(quantity, stepSize float64) []float64 {
+	var quantities []float64
+	rest := quantity
+
+	for index := range TAKE_PROFIT_LADDER {
+		stepQuantity := valueToLotSize(rest, stepSize)
+		if index < len(TAKE_PROFIT_LADDER)-1 {
+			stepQuantity = math.Floor(quantity*getTakeProfitStepShare(index)/stepSize) * stepSize
+		}
+
+		quantities = append(quantities, stepQuantity)
+		rest -= stepQuantity
+	}
+
+	return quantities
+}
+
+// --------------------------------
+
+// Live ladders are limit orders placed right after the buy, one per step
+func (bot *Bot) createTakeProfitLadderOrders(buyId int64, buyPrice, quantity float64) {
+	buy := bot.db.GetBuyById(buyId)
+	buy.ExchangeRate = buyPrice
+
+	var prices []float64
+	for step := range TAKE_PROFIT_LADDER {
+		prices = append(prices, CalcTakeProfitStepPrice(buy, step))
+	}
+
+	orderIds, quantities := bot.CreateLadderSellOrders(CANDLE_SYMBOL, prices, quantity)
+	for step, orderId := range orderIds {
+		bot.db.AddTakeProfitOrder(buyId, step, orderId, quantities[step], prices[step])
+
+		Log(fmt.Sprintf(
+			"TAKE_PROFIT_ORDER\nOrderId: %d\nStep: %d\nPrice: %f\nQuantity: %f",
+			orderId,
+			step,
+			prices[step],
+			quantities[step],
+		))
+	}
+
+	if ENABLE_FUTURES && bot.hasStopLossOrder() {
+		stopPrice := bot.stopLossSellIndicator.GetStopPrice(buy)
+		stopOrderId := bot.futuresOrderManager.CreateStopLossOrder(CANDLE_SYMBOL, stopPrice, quantity)
+		bot.db.UpdateStopOrderId(buyId, stopOrderId)
+
+		Log(fmt.Sprintf("STOP_LOSS_ORDER\nOrderId: %d\nStopPrice: %f", stopOrderId, stopPrice))
+	}
+}
+
+func (bot *Bot) CreateLadderSellOrders(symbol string, prices []float64, quantity float64) ([]int64, []float64) {
+	if ENABLE_FUTURES {
+		return bot.futuresOrderManager.CreateLadderSellOrders(symbol, prices, quantity)
+	}
+
+	return bot.orderManager.CreateLadderSellOrders(symbol, prices, quantity)
+}
+
+// Cancels the open steps of the ladder and returns their quantity, false when the buy has no ladder orders
+func (bot *Bot) cancelTakeProfitLadderOrders(buy Buy) (bool, float64) {
+	orders := bot.db.FetchTakeProfitOrders(buy.Id)
+	if len(orders) == 0 {
+		return false, 0
+	}
+
+	quantity := 0.0
+	for _, order := range orders {
+		if bot.IsBuySold(CANDLE_SYMBOL, order.OrderId) {
+			continue
+		}
+
+		Log(fmt.Sprintf("CANCEL_ORDER\nOrderId: %d\n", order.OrderId))
+		bot.CancelOrder(CANDLE_SYMBOL, order.OrderId)
+		quantity += order.Quantity
+	}
+
+	return true, quantity
+}
@@ -6,24 +6,47 @@ import (
 	"github.com/rocketlaunchr/dataframe-go"
 	"github.com/rocketlaunchr/dataframe-go/exports"
 	"math"
+	"math/rand"
 	"os"
 )
 
-func RunTest() {
+func RunTest(seed int64, spaceFileName string) {
 	// Main
 	LogAndPrint("Gen has started!")
 
-	bots := GetInitialBots()
+	// The bots are tested in goroutines, the random is used only here, so the same seed gives the same generations
+	random, seed := NewRand(seed)
+	LogAndPrint(fmt.Sprintf("Seed: %d", seed))
+
+	// The space of the run is saved next to the generations
+	space := GetDefaultSearchSpace()
+	if spaceFileName != "" {
+		space = LoadSearchSpace(spaceFileName)
+		LogAndPrint(fmt.Sprintf("Search space: %s", spaceFileName))
+	}
+	space.Export("search_space.json")
+
+	bots := GetInitialBots(random, space)
 	//bots := GetInitialBotsFromFile("initial.csv")
-	fitnessDatasets := ImportDatasets(GetDatasetDates())
-	validationDatasets := &[]Candle{}
+	fitnessSource := func() CandleSource {
+		source := NewDatasetCandleSource(GetDatasetDates())
+		return &source
+	}
+	// Only the count is kept, every bot streams its validation window from the datasets
+	validationCandlesCount := 0
 	if !NO_VALIDATION {
-		validationDatasets = ImportDatasets(GetValidationDatasetDates())
+		source := NewDatasetCandleSource(GetValidationDatasetDates())
+		validationCandlesCount = CountCandles(&source)
 	}
 
 	for generation := 0; generation < GENERATION_COUNT; generation++ {
 		var botRevenueChan = make(chan BotRevenue, 5)
-		randValidationDataset := getRandomValidationDataset(validationDatasets)
+		validationStart, validationEnd := getRandomValidationWindow(random, validationCandlesCount)
+		validationSource := func() CandleSource {
+			datasetSource := NewDatasetCandleSource(GetValidationDatasetDates())
+			source := NewWindowCandleSource(&datasetSource, validationStart, validationEnd)
+			return &source
+		}
 
 		iterator := bots.ValuesIterator(dataframe.ValuesOptions{0, 1, true})
 		for {
@@ -62,7 +85,7 @@ func RunTest() {
 
 			fmt.Println(fmt.Sprintf("Gen: %d, Bot: %d", generation, *botNumber))
 			botConfig := ConvertDataFrameToBotConfig(bot)
-			go Fitness(botConfig, *botNumber, botRevenueChan, fitnessDatasets, &randValidationDataset)
+			go Fitness(botConfig, *botNumber, botRevenueChan, fitnessSource, validationSource)
 		}
 
 		channelsCount := bots.NRows()
@@ -104,7 +127,7 @@ func RunTest() {
 		exports.ExportToCSV(context.Background(), botsCsvFile, parentBots)
 
 		bestBots := SelectNBots(BEST_BOTS_COUNT, parentBots)
-		childBots := MakeChildren(bestBots)
+		childBots := MakeChildren(random, space, bestBots)
 
 		bots = CombineParentAndChildBots(
 			SelectNBots(BEST_BOTS_FROM_PREV_GEN, bestBots),
@@ -119,25 +142,24 @@ func RunTest() {
 	}
 }
 
-func getRandomValidationDataset(validationDatasets *[]Candle) []Candle {
+// The start and end of the validation candles, as slice indexes
+func getRandomValidationWindow(random *rand.Rand, count int) (int, int) {
 	if NO_VALIDATION {
-		return []Candle{}
+		return 0, 0
 	}
 
-	count := len(*validationDatasets)
-
 	// After half of slice
 	half := int(math.Round(float64(count) / 2))
-	start := GetRandInt(0, half)
+	start := GetRandInt(random, 0, half)
 	end := count - 1
 
 	// Before half of slice
-	if GetRandInt(0, 1) == 1 {
+	if GetRandInt(random, 0, 1) == 1 {
 		start = 0
-		end = GetRandInt(half, count-1)
+		end = GetRandInt(random, half, count-1)
 	}
 
-	return (*validationDatasets)[start:end]
+	return start, end
 }
 
 func fixRevenue(revenue float64) float64 {
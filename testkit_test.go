@@ -0,0 +1,317 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Test kit: bots and indicators are built from a Config, fed with scripted candles and checked
+// through the signals, the buys and sells tables and the balance. Tests of the sell math run
+// for futures and spot, see runTestModes.
+
+var testStartTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// One row of an inline candle table, the candles are one minute long from testStartTime
+type testCandle struct {
+	Open     float64
+	High     float64
+	Low      float64
+	Close    float64
+	Volume   float64
+	TakerBuy float64
+}
+
+type testSell struct {
+	BuyId        int64
+	Coins        float64
+	ExchangeRate float64
+	Revenue      float64
+}
+
+// Signals and table sizes after one candle
+type testStep struct {
+	Candle      Candle
+	BuySignals  map[string]bool
+	SellSignals map[string][]int64
+	BuysCount   int
+	SellsCount  int
+}
+
+// ENABLE_FUTURES is switched back when the test is finished
+func setTestFutures(t *testing.T, enabled bool) {
+	previous := ENABLE_FUTURES
+	ENABLE_FUTURES = enabled
+	t.Cleanup(func() { ENABLE_FUTURES = previous })
+}
+
+// Runs the test once for futures and once for spot
+func runTestModes(t *testing.T, test func(t *testing.T)) {
+	for _, name := range []string{"futures", "spot"} {
+		t.Run(name, func(t *testing.T) {
+			setTestFutures(t, name == "futures")
+			test(t)
+		})
+	}
+}
+
+func newTestConfig() Config {
+	return Config{
+		HighSellPercentage:        1,
+		TotalMoneyAmount:          100,
+		Leverage:                  10,
+		BigFallCandlesCount:       5,
+		BigFallSmoothPeriod:       1,
+		BigFallPercentage:         2,
+		StopLossPercentage:        5,
+		RegimeMask:                1<<RegimesCount - 1,
+		TradingWindowHoursCount:   24,
+		TradingWeekdaysMask:       1<<7 - 1,
+		DecayTakeProfitMinutes:    60,
+		FuturesAvgSellTimeMinutes: 60,
+	}
+}
+
+func newTestCandle(index int, row testCandle) Candle {
+	openTime := testStartTime.Add(time.Duration(index) * time.Minute)
+
+	return Candle{
+		Symbol:                  CANDLE_SYMBOL,
+		OpenTime:                openTime,
+		CloseTime:               openTime.Add(time.Minute - time.Millisecond),
+		OpenPrice:               row.Open,
+		HighPrice:               row.High,
+		LowPrice:                row.Low,
+		ClosePrice:              row.Close,
+		Volume:                  row.Volume,
+		QuoteAssetVolume:        row.Volume * row.Close,
+		TakerBuyBaseAssetVolume: row.TakerBuy,
+		IsClosed:                true,
+	}
+}
+
+func newTestCandles(rows []testCandle) []Candle {
+	var candles []Candle
+	for index, row := range rows {
+		candles = append(candles, newTestCandle(index, row))
+	}
+
+	return candles
+}
+
+// Flat candles, open, high, low and close are the same price
+func newTestPriceCandles(prices ...float64) []Candle {
+	var rows []testCandle
+	for _, price := range prices {
+		rows = append(rows, testCandle{Open: price, High: price, Low: price, Close: price, Volume: 1, TakerBuy: 0.5})
+	}
+
+	return newTestCandles(rows)
+}
+
+// Fixtures are kline CSVs of the datasets format in testdata
+func loadTestCandles(fileName string) []Candle {
+	source := NewCsvCandleSource(filepath.Join("testdata", fileName), CANDLE_SYMBOL)
+
+	return CollectCandles(&source)
+}
+
+// Empty expressions keep the signals of the config
+func newTestBot(t *testing.T, config Config, buyExpression, sellExpression string) *Bot {
+	bot := NewBot(&config)
+	t.Cleanup(bot.Kill)
+
+	if buyExpression != "" {
+		bot.SetBuySignal(buyExpression)
+	}
+
+	if sellExpression != "" {
+		bot.SetSellSignal(sellExpression)
+	}
+
+	return &bot
+}
+
+// The bot after a restart: new indicators on the same database, with the saved states restored
+func restartTestBot(bot *Bot, buyExpression, sellExpression string) *Bot {
+	restarted := NewBot(bot.Config)
+	restarted.Kill()
+	restarted.db = bot.db
+
+	restarted.SetBuySignal(buyExpression)
+	restarted.SetSellSignal(sellExpression)
+	restarted.RestoreIndicatorStates()
+
+	return &restarted
+}
+
+func runTestCandles(bot *Bot, candles []Candle) []testStep {
+	var steps []testStep
+
+	for _, candle := range candles {
+		bot.DoStuff(candle)
+
+		step := testStep{
+			Candle:      candle,
+			BuySignals:  map[string]bool{},
+			SellSignals: map[string][]int64{},
+			BuysCount:   bot.db.GetBuysCount(),
+			SellsCount:  len(fetchTestSells(bot.db)),
+		}
+		for name, hasSignal := range bot.buySignal.signals {
+			step.BuySignals[name] = hasSignal
+		}
+		for name, buys := range bot.sellSignal.signals {
+			for _, buy := range buys {
+				step.SellSignals[name] = append(step.SellSignals[name], buy.Id)
+			}
+		}
+
+		steps = append(steps, step)
+	}
+
+	return steps
+}
+
+func newTestBuffer(config *Config) *Buffer {
+	buffer := NewBuffer(resolveBufferSize(config))
+	for _, interval := range HIGHER_TIMEFRAMES {
+		buffer.AddTimeframe(interval, HIGHER_TIMEFRAME_BUFFER_SIZE)
+	}
+
+	return &buffer
+}
+
+func newTestDatabase(t *testing.T, config *Config) *Database {
+	db := NewDatabase(*config)
+	t.Cleanup(db.Close)
+
+	return &db
+}
+
+// Signal of a single buy indicator after every candle
+func runTestBuyIndicator(buffer *Buffer, indicator BuyIndicator, candles []Candle) []bool {
+	var signals []bool
+
+	for _, candle := range candles {
+		buffer.AddCandle(candle)
+		indicator.Update()
+		signals = append(signals, indicator.HasSignal())
+	}
+
+	return signals
+}
+
+// Buys to sell of a single sell indicator after every candle
+func runTestSellIndicator(buffer *Buffer, indicator SellIndicator, candles []Candle) [][]Buy {
+	var signals [][]Buy
+
+	for _, candle := range candles {
+		buffer.AddCandle(candle)
+		indicator.Update()
+		_, buys := indicator.HasSignal()
+		signals = append(signals, buys)
+	}
+
+	return signals
+}
+
+// Buy made on the last candle of the buffer, like the bot does it
+func addTestBuy(db *Database, buffer *Buffer, indicators ...SellIndicator) Buy {
+	candle := buffer.GetLastCandle()
+	config := db.config
+	coinsCount := config.TotalMoneyAmount / candle.ClosePrice
+	if ENABLE_FUTURES {
+		coinsCount = config.TotalMoneyAmount * float64(config.Leverage) / candle.ClosePrice
+	}
+
+	result := db.AddBuy(CANDLE_SYMBOL, coinsCount, candle.ClosePrice, CalcUpperPrice(candle.ClosePrice, config.HighSellPercentage), candle.CloseTime, config.TotalMoneyAmount)
+	buyId, _ := result.LastInsertId()
+	for _, indicator := range indicators {
+		indicator.RunAfterBuy(buyId)
+	}
+
+	return db.GetBuyById(buyId)
+}
+
+func fetchTestBuys(db *Database) []Buy {
+	buys := []Buy{}
+	rows, err := db.connect.Query(`SELECT * FROM buys ORDER BY id`)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		buys = append(buys, scanBuy(rows))
+	}
+
+	return buys
+}
+
+func fetchTestSells(db *Database) []testSell {
+	sells := []testSell{}
+	rows, err := db.connect.Query(`SELECT buy_id, coins, exchange_rate, revenue FROM sells ORDER BY id`)
+	if err != nil {
+		panic(err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sell := testSell{}
+		rows.Scan(&sell.BuyId, &sell.Coins, &sell.ExchangeRate, &sell.Revenue)
+		sells = append(sells, sell)
+	}
+
+	return sells
+}
+
+func buyIds(buys []Buy) []int64 {
+	ids := []int64{}
+	for _, buy := range buys {
+		ids = append(ids, buy.Id)
+	}
+
+	return ids
+}
+
+// --------------------------------
+
+func assertFloat(t *testing.T, name string, expected, actual float64) {
+	t.Helper()
+
+	if math.Abs(expected-actual) > 0.000001 {
+		t.Errorf("%s: expected %f, got %f", name, expected, actual)
+	}
+}
+
+func assertSignals(t *testing.T, expected, actual []bool) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Fatalf("expected %d signals, got %d", len(expected), len(actual))
+	}
+
+	for index := range expected {
+		if expected[index] != actual[index] {
+			t.Errorf("candle %d: expected signal %t, got %t", index, expected[index], actual[index])
+		}
+	}
+}
+
+func assertIds(t *testing.T, name string, expected, actual []int64) {
+	t.Helper()
+
+	if len(expected) != len(actual) {
+		t.Errorf("%s: expected %v, got %v", name, expected, actual)
+		return
+	}
+
+	for index := range expected {
+		if expected[index] != actual[index] {
+			t.Errorf("%s: expected %v, got %v", name, expected, actual)
+			return
+		}
+	}
+}
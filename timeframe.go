@@ -0,0 +1,94 @@
+package main
+
+import "time"
+
+// Higher timeframe candles built from the base stream, so backtests and the live bot see the same candles.
+// Only closed candles get to the buffer, the forming one is never visible to indicators.
+type TimeframeBuffer struct {
+	Buffer
+	interval  string
+	duration  time.Duration
+	candle    Candle
+	isForming bool
+}
+
+func NewTimeframeBuffer(interval string, maxSize int) TimeframeBuffer {
+	return TimeframeBuffer{
+		Buffer:   NewBuffer(maxSize),
+		interval: interval,
+		duration: GetCandleIntervalDuration(interval),
+	}
+}
+
+func (timeframe *TimeframeBuffer) AddBaseCandle(candle Candle) {
+	openTime := candle.OpenTime.Truncate(timeframe.duration)
+
+	if timeframe.isForming && !timeframe.candle.OpenTime.Equal(openTime) {
+		// A gap in the base stream, close what we have
+		timeframe.closeCandle()
+	}
+
+	if !timeframe.isForming {
+		timeframe.candle = Candle{
+			Symbol:    candle.Symbol,
+			OpenTime:  openTime,
+			OpenPrice: candle.OpenPrice,
+			HighPrice: candle.HighPrice,
+			LowPrice:  candle.LowPrice,
+		}
+		timeframe.isForming = true
+	}
+
+	timeframe.candle.CloseTime = openTime.Add(timeframe.duration - time.Millisecond)
+	timeframe.candle.ClosePrice = candle.ClosePrice
+	if candle.HighPrice > timeframe.candle.HighPrice {
+		timeframe.candle.HighPrice = candle.HighPrice
+	}
+	if candle.LowPrice < timeframe.candle.LowPrice {
+		timeframe.candle.LowPrice = candle.LowPrice
+	}
+	timeframe.candle.Volume += candle.Volume
+	timeframe.candle.QuoteAssetVolume += candle.QuoteAssetVolume
+	timeframe.candle.NumberOfTrades += candle.NumberOfTrades
+	timeframe.candle.TakerBuyBaseAssetVolume += candle.TakerBuyBaseAssetVolume
+	timeframe.candle.TakerBuyQuoteAssetVolume += candle.TakerBuyQuoteAssetVolume
+
+	if !candle.CloseTime.Before(timeframe.candle.CloseTime) {
+		timeframe.closeCandle()
+	}
+}
+
+func (timeframe *TimeframeBuffer) closeCandle() {
+	timeframe.candle.IsClosed = true
+	timeframe.AddCandle(timeframe.candle)
+	timeframe.isForming = false
+}
+
+func (timeframe *TimeframeBuffer) GetInterval() string {
+	return timeframe.interval
+}
+
+// --------------------------------
+
+func (buffer *Buffer) AddTimeframe(interval string, maxSize int) {
+	if buffer.timeframes == nil {
+		buffer.timeframes = map[string]*TimeframeBuffer{}
+	}
+
+	if _, ok := buffer.timeframes[interval]; ok {
+		return
+	}
+
+	timeframe := NewTimeframeBuffer(interval, maxSize)
+	buffer.timeframes[interval] = &timeframe
+	buffer.timeframesOrder = append(buffer.timeframesOrder, &timeframe)
+}
+
+func (buffer *Buffer) GetTimeframe(interval string) *Buffer {
+	timeframe, ok := buffer.timeframes[interval]
+	if !ok {
+		panic("Unknown timeframe: " + interval + ", add it to HIGHER_TIMEFRAMES")
+	}
+
+	return &timeframe.Buffer
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Per candle trace of indicator values, see ENABLE_TRACE.
+// One row per value: time, side, indicator, name, value. The "signal" row is 1/0 for buys
+// and the count of buys to sell for sells.
+type TraceValue struct {
+	Name  string
+	Value float64
+}
+
+type TraceableIndicator interface {
+	GetTraceValues() []TraceValue
+}
+
+type TraceRow struct {
+	Indicator string
+	Values    []TraceValue
+}
+
+var traceWriter *csv.Writer
+
+func TraceAddRows(candle Candle, side string, rows []TraceRow) {
+	if !canTrace() || !isInTraceRange(candle.CloseTime) {
+		return
+	}
+
+	initTrace()
+
+	for _, row := range rows {
+		for _, value := range row.Values {
+			err := traceWriter.Write([]string{
+				FormatTime(candle.CloseTime),
+				side,
+				row.Indicator,
+				value.Name,
+				fmt.Sprintf("%f", value.Value),
+			})
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	traceWriter.Flush()
+}
+
+func FormatTraceRows(rows []TraceRow) string {
+	var lines []string
+
+	for _, row := range rows {
+		var values []string
+		for _, value := range row.Values {
+			values = append(values, fmt.Sprintf("%s=%f", value.Name, value.Value))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s: %s", row.Indicator, strings.Join(values, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func initTrace() {
+	if traceWriter != nil {
+		return
+	}
+
+	file, err := os.Create(TRACE_FILE_NAME)
+	if err != nil {
+		panic(err)
+	}
+
+	traceWriter = csv.NewWriter(file)
+	if err := traceWriter.Write([]string{"time", "side", "indicator", "name", "value"}); err != nil {
+		panic(err)
+	}
+}
+
+func isInTraceRange(candleTime time.Time) bool {
+	if TRACE_FROM != "" && candleTime.Before(ConvertDateStringToTime(TRACE_FROM)) {
+		return false
+	}
+
+	if TRACE_TO != "" && candleTime.After(ConvertDateStringToTime(TRACE_TO)) {
+		return false
+	}
+
+	return true
+}
+
+// Bots of the genetic run are traced only when there is a single one, like plots
+func canTrace() bool {
+	return ENABLE_TRACE && (IS_REAL_ENABLED || IS_REPLAY_ENABLED || canPlot())
+}
+
+// --------------------------------
+
+func boolToTraceValue(value bool) float64 {
+	if value {
+		return 1
+	}
+
+	return 0
+}
+
+func (signal *BuySignal) GetTraceRows() []TraceRow {
+	var rows []TraceRow
+
+	for _, name := range signal.names {
+		values := []TraceValue{{Name: "signal", Value: boolToTraceValue(signal.signals[name])}}
+		if traceable, ok := signal.indicators[name].(TraceableIndicator); ok {
+			values = append(values, traceable.GetTraceValues()...)
+		}
+
+		rows = append(rows, TraceRow{Indicator: name, Values: values})
+	}
+
+	return rows
+}
+
+func (signal *SellSignal) GetTraceRows() []TraceRow {
+	var rows []TraceRow
+
+	for _, name := range signal.names {
+		values := []TraceValue{{Name: "signal", Value: float64(len(signal.signals[name]))}}
+		if traceable, ok := signal.indicators[name].(TraceableIndicator); ok {
+			values = append(values, traceable.GetTraceValues()...)
+		}
+
+		rows = append(rows, TraceRow{Indicator: name, Values: values})
+	}
+
+	return rows
+}
+
+func buyIdsTraceValues(prefix string, values map[int64]float64) []TraceValue {
+	var buyIds []int64
+	for buyId := range values {
+		buyIds = append(buyIds, buyId)
+	}
+	sort.Slice(buyIds, func(i, j int) bool { return buyIds[i] < buyIds[j] })
+
+	var result []TraceValue
+	for _, buyId := range buyIds {
+		result = append(result, TraceValue{Name: fmt.Sprintf("%s_%d", prefix, buyId), Value: values[buyId]})
+	}
+
+	return result
+}